@@ -0,0 +1,181 @@
+package gooh
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type RouteConstraints struct {
+	Headers  map[string]string
+	Queries  map[string]string
+	Consumes []string
+	Produces []string
+}
+
+func mergeRouteConstraints(c *RouteConstraints, o *RouteConstraints) *RouteConstraints {
+	if c == nil {
+		return o
+	}
+	if o == nil {
+		return c
+	}
+
+	m := &RouteConstraints{Headers: map[string]string{}, Queries: map[string]string{}}
+	for _, constraints := range []*RouteConstraints{c, o} {
+		for key, value := range constraints.Headers {
+			m.Headers[key] = value
+		}
+		for key, value := range constraints.Queries {
+			m.Queries[key] = value
+		}
+	}
+
+	m.Consumes = c.Consumes
+	if len(o.Consumes) > 0 {
+		m.Consumes = o.Consumes
+	}
+	m.Produces = c.Produces
+	if len(o.Produces) > 0 {
+		m.Produces = o.Produces
+	}
+
+	return m
+}
+
+func (c *RouteConstraints) equal(o *RouteConstraints) bool {
+	return equalValues(c.Headers, o.Headers, http.CanonicalHeaderKey) && equalValues(c.Queries, o.Queries, nil) &&
+		equalMediaTypes(c.Consumes, o.Consumes) && equalMediaTypes(c.Produces, o.Produces)
+}
+
+func equalValues(a map[string]string, b map[string]string, canonical func(string) string) bool {
+	if canonical == nil {
+		canonical = func(s string) string { return s }
+	}
+
+	c := make(map[string]string)
+	for key, value := range a {
+		c[canonical(key)] = value
+	}
+	d := make(map[string]string)
+	for key, value := range b {
+		d[canonical(key)] = value
+	}
+
+	if len(c) != len(d) {
+		return false
+	}
+	for key, value := range c {
+		if v, ok := d[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+func equalMediaTypes(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, t := range a {
+		if !containsFold(b, t) {
+			return false
+		}
+	}
+	for _, t := range b {
+		if !containsFold(a, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *RouteConstraints) check(req *http.Request) error {
+	if req == nil {
+		return ErrRouteNotFound
+	}
+
+	for key, value := range c.Headers {
+		values := req.Header.Values(key)
+		if len(values) == 0 || (len(value) > 0 && !containsString(values, value)) {
+			return ErrRouteNotFound
+		}
+	}
+
+	if len(c.Queries) > 0 {
+		query := req.URL.Query()
+		for key, value := range c.Queries {
+			if !query.Has(key) || (len(value) > 0 && !containsString(query[key], value)) {
+				return ErrRouteNotFound
+			}
+		}
+	}
+
+	if len(c.Consumes) > 0 {
+		t, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || !matchMediaTypes(c.Consumes, t) {
+			return &UnsupportedMediaTypeError{"unsupported media type", c.Consumes}
+		}
+	}
+
+	if len(c.Produces) > 0 && !isAcceptable(req.Header.Values("Accept"), c.Produces) {
+		return &NotAcceptableError{"not acceptable", c.Produces}
+	}
+
+	return nil
+}
+
+func matchMediaType(r string, t string) bool {
+	if r == "*/*" || strings.EqualFold(r, t) {
+		return true
+	}
+
+	if strings.HasSuffix(r, "/*") {
+		return strings.HasPrefix(strings.ToLower(t), strings.ToLower(strings.TrimSuffix(r, "*")))
+	}
+	return false
+}
+
+func matchMediaTypes(ranges []string, t string) bool {
+	for _, r := range ranges {
+		if matchMediaType(r, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAcceptable(accept []string, types []string) bool {
+	if len(accept) == 0 {
+		return true
+	}
+
+	for _, header := range accept {
+		for _, value := range strings.Split(header, ",") {
+			r, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+				continue
+			}
+
+			for _, t := range types {
+				if matchMediaType(r, t) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
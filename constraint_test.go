@@ -0,0 +1,112 @@
+package gooh
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_RouteConstraints_Merge(t *testing.T) {
+	exp := "1;2;application/json;text/csv"
+
+	c := mergeRouteConstraints(
+		&RouteConstraints{Headers: map[string]string{"X-A": "1"}, Consumes: []string{"application/json"}, Produces: []string{"application/json"}},
+		&RouteConstraints{Headers: map[string]string{"X-B": "2"}, Produces: []string{"text/csv"}},
+	)
+	val := c.Headers["X-A"] + ";" + c.Headers["X-B"] + ";" + c.Consumes[0] + ";" + c.Produces[0]
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Merge_Nil(t *testing.T) {
+	exp := &RouteConstraints{}
+	val := mergeRouteConstraints(nil, exp)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Equal(t *testing.T) {
+	exp := "true;false"
+
+	c := &RouteConstraints{Headers: map[string]string{"x-beta": ""}, Consumes: []string{"application/json", "text/csv"}}
+	o := &RouteConstraints{Headers: map[string]string{"X-Beta": ""}, Queries: map[string]string{}, Consumes: []string{"text/csv", "application/json"}}
+	d := &RouteConstraints{Headers: map[string]string{"X-Beta": "1"}, Consumes: []string{"text/csv", "application/json"}}
+	val := fmt.Sprintf("%v;%v", c.equal(o), c.equal(d))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Check_HeaderValue(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	c := &RouteConstraints{Headers: map[string]string{"X-Beta": "1"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Beta", "2")
+	val := c.check(req)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Check_QueryValue(t *testing.T) {
+	var exp error
+
+	c := &RouteConstraints{Queries: map[string]string{"format": "csv"}}
+	req := httptest.NewRequest("GET", "/?format=json&format=csv", nil)
+	val := c.check(req)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Check_MissingContentType(t *testing.T) {
+	exp := "unsupported media type"
+
+	c := &RouteConstraints{Consumes: []string{"application/json"}}
+	req := httptest.NewRequest("POST", "/", nil)
+	val := c.check(req).Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_RouteConstraints_Check_NoAccept(t *testing.T) {
+	var exp error
+
+	c := &RouteConstraints{Produces: []string{"application/json"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	val := c.check(req)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_MatchMediaType(t *testing.T) {
+	exp := "true;true;true;false"
+	val := ""
+
+	for i, pair := range [][]string{{"*/*", "text/csv"}, {"text/*", "TEXT/CSV"}, {"application/json", "application/json"}, {"text/*", "application/json"}} {
+		if i > 0 {
+			val += ";"
+		}
+		if matchMediaType(pair[0], pair[1]) {
+			val += "true"
+		} else {
+			val += "false"
+		}
+	}
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
@@ -1,7 +1,8 @@
 package gooh
 
 var (
-	ErrRouteNotFound = &RouteNotFoundError{"route not found"}
+	ErrRouteNotFound    = &RouteNotFoundError{"route not found"}
+	ErrResponseComplete = &ResponseCompleteError{"response complete"}
 )
 
 type RouteNotFoundError struct {
@@ -12,6 +13,94 @@ func (e RouteNotFoundError) Error() string {
 	return e.Msg
 }
 
+type InvalidParameterError struct {
+	Msg string
+}
+
+func (e InvalidParameterError) Error() string {
+	return e.Msg
+}
+
+type InvalidEncodingError struct {
+	Msg string
+}
+
+func (e InvalidEncodingError) Error() string {
+	return e.Msg
+}
+
+type MethodNotAllowedError struct {
+	Msg     string
+	Allowed []string
+}
+
+func (e MethodNotAllowedError) Error() string {
+	return e.Msg
+}
+
+type UnsupportedMediaTypeError struct {
+	Msg      string
+	Consumes []string
+}
+
+func (e UnsupportedMediaTypeError) Error() string {
+	return e.Msg
+}
+
+type NotAcceptableError struct {
+	Msg      string
+	Produces []string
+}
+
+func (e NotAcceptableError) Error() string {
+	return e.Msg
+}
+
+type DuplicateRouteError struct {
+	Msg   string
+	Route string
+}
+
+func (e DuplicateRouteError) Error() string {
+	return e.Msg
+}
+
+type AmbiguousRouteError struct {
+	Msg      string
+	Route    string
+	Conflict string
+}
+
+func (e AmbiguousRouteError) Error() string {
+	return e.Msg
+}
+
+type InvalidPatternError struct {
+	Msg   string
+	Route string
+}
+
+func (e InvalidPatternError) Error() string {
+	return e.Msg
+}
+
+type InvalidMethodError struct {
+	Msg   string
+	Route string
+}
+
+func (e InvalidMethodError) Error() string {
+	return e.Msg
+}
+
+type ResponseCompleteError struct {
+	Msg string
+}
+
+func (e ResponseCompleteError) Error() string {
+	return e.Msg
+}
+
 type PanicError struct {
 	Err interface{}
 }
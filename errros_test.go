@@ -15,6 +15,106 @@ func Test_RouteNotFoundError_Error(t *testing.T) {
 	}
 }
 
+func Test_InvalidParameterError_Error(t *testing.T) {
+	exp := "v"
+	err := InvalidParameterError{"v"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_InvalidEncodingError_Error(t *testing.T) {
+	exp := "v"
+	err := InvalidEncodingError{"v"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_MethodNotAllowedError_Error(t *testing.T) {
+	exp := "v"
+	err := MethodNotAllowedError{"v", []string{"GET"}}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_UnsupportedMediaTypeError_Error(t *testing.T) {
+	exp := "v"
+	err := UnsupportedMediaTypeError{"v", []string{"application/json"}}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_NotAcceptableError_Error(t *testing.T) {
+	exp := "v"
+	err := NotAcceptableError{"v", []string{"application/json"}}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_DuplicateRouteError_Error(t *testing.T) {
+	exp := "v"
+	err := DuplicateRouteError{"v", "/users"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_AmbiguousRouteError_Error(t *testing.T) {
+	exp := "v"
+	err := AmbiguousRouteError{"v", "/users/:name", "/users/:id"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_InvalidPatternError_Error(t *testing.T) {
+	exp := "v"
+	err := InvalidPatternError{"v", "/users/:id{"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_InvalidMethodError_Error(t *testing.T) {
+	exp := "v"
+	err := InvalidMethodError{"v", "/users"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_ResponseCompleteError_Error(t *testing.T) {
+	exp := "v"
+	err := ResponseCompleteError{"v"}
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
 func Test_PanicError_Error_String(t *testing.T) {
 	exp := "v"
 	err := PanicError{"v"}
@@ -1,13 +1,18 @@
 package gooh
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+var versionRegexp = regexp.MustCompile("^v[0-9]+(\\.[0-9]+(\\.[0-9]+)?)?$")
+
 type Version struct {
 	Major int
 	Minor int
@@ -35,7 +40,7 @@ func (v Version) String() string {
 func NewVersion(s string) *Version {
 	v := &Version{}
 
-	if matched, _ := regexp.MatchString("^v[0-9]+(\\.[0-9]+(\\.[0-9]+)?)?$", s); matched {
+	if versionRegexp.MatchString(s) {
 		numbers := strings.Split(strings.TrimPrefix(s, "v"), ".")
 		for i, value := range numbers {
 			number, _ := strconv.Atoi(value)
@@ -64,10 +69,85 @@ type Request struct {
 	*http.Request
 	ApiVersion *Version
 	Context    Context
+	handler    *MiddlewareHandler
+	params     map[string]interface{}
 }
 
 type Response struct {
 	http.ResponseWriter
+	status    int
+	size      int
+	committed bool
+}
+
+func (r *Response) Status() int {
+	return r.status
+}
+
+func (r *Response) Size() int {
+	return r.size
+}
+
+func (r *Response) Committed() bool {
+	return r.committed
+}
+
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *Response) WriteHeader(code int) {
+	if r.committed {
+		return
+	}
+
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	r.status = code
+	r.committed = true
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *Response) Write(b []byte) (int, error) {
+	if !r.committed {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+func (r *Response) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if !r.committed {
+			r.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		r.committed = true
+	}
+	return conn, rw, err
+}
+
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	if p, ok := r.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
 }
 
 func (r *Response) WriteJson(d interface{}) error {
@@ -83,6 +163,8 @@ func (r *Response) WriteJson(d interface{}) error {
 
 type MiddlewareHandler func(*App, *Request, *Response) error
 
+type NextMiddlewareHandler func(*App, *Request, *Response, func() error) error
+
 type ErrorHandler func(*App, *Request, *Response, error)
 
 type App struct {
@@ -109,6 +191,21 @@ func (a *App) AddMiddlewareHandler(h MiddlewareHandler) {
 	}
 }
 
+func (a *App) AddNextMiddlewareHandler(h NextMiddlewareHandler) {
+	if h != nil {
+		i := len(a.mdwHandlers)
+		a.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+			next := func() error {
+				return a.serveMiddlewares(req, res, i+1)
+			}
+			if err := h(app, req, res, next); err != nil {
+				return err
+			}
+			return ErrResponseComplete
+		})
+	}
+}
+
 func (a *App) AddErrorHanlder(h ErrorHandler) {
 	if h != nil {
 		a.errHandlers = append(a.errHandlers, &h)
@@ -116,8 +213,26 @@ func (a *App) AddErrorHanlder(h ErrorHandler) {
 }
 
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	req := &Request{r, &Version{}, nil}
-	res := &Response{w}
+	a.serveHTTP(w, r, nil)
+}
+
+func (a *App) Handler(h RouteHandler, params ...string) http.Handler {
+	var handler MiddlewareHandler = func(app *App, req *Request, res *Response) error {
+		p := make(map[string]string)
+		for _, name := range params {
+			p[name] = req.Request.PathValue(name)
+		}
+		return h(app, req, res, p)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.serveHTTP(w, r, &handler)
+	})
+}
+
+func (a *App) serveHTTP(w http.ResponseWriter, r *http.Request, h *MiddlewareHandler) {
+	req := &Request{r, &Version{}, nil, h, nil}
+	res := &Response{ResponseWriter: w}
 
 	defer func() {
 		if err := recover(); err != nil {
@@ -125,10 +240,50 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	for _, handler := range a.mdwHandlers {
+	if err := a.serveMiddlewares(req, res, 0); err != nil {
+		a.handleError(a, req, res, err)
+	}
+}
+
+func (a *App) serveMiddlewares(req *Request, res *Response, i int) error {
+	for ; i <= len(a.mdwHandlers); i++ {
+		handler := req.handler
+		if i < len(a.mdwHandlers) {
+			handler = a.mdwHandlers[i]
+		}
+		if handler == nil {
+			continue
+		}
+
 		if err := (*handler)(a, req, res); err != nil {
-			a.handleError(a, req, res, err)
-			return
+			if errors.Is(err, ErrResponseComplete) {
+				return nil
+			}
+			return err
 		}
 	}
+	return nil
+}
+
+func HTTPMiddleware(m func(http.Handler) http.Handler) NextMiddlewareHandler {
+	return func(app *App, req *Request, res *Response, next func() error) error {
+		var err error
+		called := false
+		w, r := res.ResponseWriter, req.Request
+		outer := &Response{w, res.status, res.size, res.committed}
+
+		inner := http.HandlerFunc(func(iw http.ResponseWriter, ir *http.Request) {
+			called = true
+			res.ResponseWriter, req.Request = iw, ir
+			err = next()
+		})
+		m(inner).ServeHTTP(outer, r)
+
+		res.ResponseWriter, req.Request = w, r
+		res.status, res.size, res.committed = outer.status, outer.size, outer.committed
+		if !called {
+			return ErrResponseComplete
+		}
+		return err
+	}
 }
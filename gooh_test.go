@@ -1,7 +1,12 @@
 package gooh
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -327,3 +332,445 @@ func Test_App_ServeHTTP_PanicWithError(t *testing.T) {
 		t.Errorf("Expected '%v', got '%v'", exp, gvar)
 	}
 }
+
+func Test_App_AddNextMiddlewareHandler_Invalid(t *testing.T) {
+	exp := 0
+	app := new(App)
+	app.AddNextMiddlewareHandler(nil)
+	val := len(app.mdwHandlers)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_App_ServeHTTP_NextFnAndOrder(t *testing.T) {
+	exp := "a1;b;c;a2"
+	val := ""
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		val += "a1;"
+		err := next()
+		val += "a2"
+		return err
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		val += "b;"
+		return nil
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		val += "c;"
+		return nil
+	})
+	app.ServeHTTP(nil, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_App_ServeHTTP_NextNotCalled(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		gvar = 7
+		return nil
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		gvar = 3
+		return nil
+	})
+	app.ServeHTTP(nil, nil)
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_ServeHTTP_NextNested(t *testing.T) {
+	exp := "a1;b1;c;b2;a2;d"
+	val := ""
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		val += "a1;"
+		err := next()
+		val += "a2;"
+		return err
+	})
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		val += "b1;"
+		err := next()
+		val += "b2;"
+		return err
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		val += "c;"
+		return errors.New("error")
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		val += "d"
+	})
+	app.ServeHTTP(nil, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_App_ServeHTTP_NextRecoverError(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		if err := next(); err != nil && err.Error() == "error" {
+			gvar = 7
+		}
+		return nil
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		return errors.New("error")
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		gvar = 3
+	})
+	app.ServeHTTP(nil, nil)
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_ServeHTTP_ResponseComplete(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		gvar = 7
+		return ErrResponseComplete
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		gvar = 3
+		return nil
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		gvar = 5
+	})
+	app.ServeHTTP(nil, nil)
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_ServeHTTP_WrappedResponseComplete(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		gvar = 7
+		return fmt.Errorf("cached: %w", ErrResponseComplete)
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		gvar = 3
+		return nil
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		gvar = 5
+	})
+	app.ServeHTTP(nil, nil)
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_ServeHTTP_NextResponseComplete(t *testing.T) {
+	exp := "a1;b;a2:<nil>"
+	val := ""
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, res *Response, next func() error) error {
+		val += "a1;"
+		err := next()
+		val += "a2:" + fmt.Sprint(err)
+		return err
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		val += "b;"
+		return ErrResponseComplete
+	})
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		val += "c;"
+		return nil
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		val += "d"
+	})
+	app.ServeHTTP(nil, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_Write_ImplicitStatus(t *testing.T) {
+	exp := "200;5;true"
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	res.Write([]byte("hello"))
+	val := fmt.Sprintf("%v;%v;%v", res.Status(), res.Size(), res.Committed())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_WriteHeader_Status(t *testing.T) {
+	exp := "404;0;true"
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	res.WriteHeader(404)
+	val := fmt.Sprintf("%v;%v;%v", res.Status(), res.Size(), res.Committed())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_WriteHeader_Twice(t *testing.T) {
+	exp := 401
+	rec := httptest.NewRecorder()
+	res := &Response{ResponseWriter: rec}
+	res.WriteHeader(401)
+	res.WriteHeader(500)
+
+	if res.Status() != exp || rec.Code != exp {
+		t.Errorf("Expected '%v', got '%v' and '%v'", exp, res.Status(), rec.Code)
+	}
+}
+
+func Test_Response_WriteHeader_Informational(t *testing.T) {
+	exp := "0;false"
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	res.WriteHeader(103)
+	val := fmt.Sprintf("%v;%v", res.Status(), res.Committed())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_Committed_Empty(t *testing.T) {
+	exp := false
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	val := res.Committed()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_Flush_Supported(t *testing.T) {
+	exp := true
+	rec := httptest.NewRecorder()
+	res := &Response{ResponseWriter: rec}
+	res.Flush()
+	val := rec.Flushed && res.Committed()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_Hijack_NotSupported(t *testing.T) {
+	exp := http.ErrNotSupported
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	_, _, val := res.Hijack()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_Push_NotSupported(t *testing.T) {
+	exp := http.ErrNotSupported
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	val := res.Push("/style.css", nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Response_WriteJson_Size(t *testing.T) {
+	exp := "application/json;7"
+	res := &Response{ResponseWriter: httptest.NewRecorder()}
+	res.WriteJson([]int{1, 2, 3})
+	val := fmt.Sprintf("%v;%v", res.Header().Get("Content-Type"), res.Size())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+type upperResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w upperResponseWriter) Write(b []byte) (int, error) {
+	return w.ResponseWriter.Write([]byte(strings.ToUpper(string(b)) + "!"))
+}
+
+func Test_HTTPMiddleware_Continue(t *testing.T) {
+	exp := "value;hello;after"
+	app := new(App)
+	app.AddNextMiddlewareHandler(HTTPMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "key", "value")))
+			w.Write([]byte(";after"))
+		})
+	}))
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		res.Write([]byte(req.Request.Context().Value("key").(string) + ";hello"))
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	val := rec.Body.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_HTTPMiddleware_Stop(t *testing.T) {
+	exp := "401;false"
+	called := false
+	app := new(App)
+	app.AddNextMiddlewareHandler(HTTPMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(401)
+		})
+	}))
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		called = true
+		return nil
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		called = true
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	val := fmt.Sprintf("%v;%v", rec.Code, called)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_HTTPMiddleware_WrappedWriter(t *testing.T) {
+	exp := "HELLO!;6;true"
+	var res *Response
+	app := new(App)
+	app.AddNextMiddlewareHandler(func(app *App, req *Request, r *Response, next func() error) error {
+		res = r
+		return next()
+	})
+	app.AddNextMiddlewareHandler(HTTPMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(upperResponseWriter{w}, r)
+		})
+	}))
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		res.Write([]byte("hello"))
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	val := fmt.Sprintf("%v;%v;%v", rec.Body.String(), res.Size(), res.Committed())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_HTTPMiddleware_Error(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddNextMiddlewareHandler(HTTPMiddleware(func(h http.Handler) http.Handler {
+		return h
+	}))
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		return errors.New("error")
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		if err.Error() == "error" {
+			gvar = 7
+		}
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_Handler_ServeMux(t *testing.T) {
+	exp := "middleware;id:7"
+	app := new(App)
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		res.Write([]byte("middleware;"))
+		return nil
+	})
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}", app.Handler(func(app *App, req *Request, res *Response, pms map[string]string) error {
+		res.Write([]byte("id:" + pms["id"]))
+		return nil
+	}, "id"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/users/7", nil))
+	val := rec.Body.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_App_Handler_Error(t *testing.T) {
+	gvar = 0
+	exp := 7
+	app := new(App)
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		return nil
+	})
+	app.AddErrorHanlder(func(app *App, req *Request, res *Response, err error) {
+		if err.Error() == "error" {
+			gvar = 7
+		}
+	})
+	h := app.Handler(func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_App_Handler_ResponseComplete(t *testing.T) {
+	gvar = 0
+	exp := 0
+	app := new(App)
+	app.AddMiddlewareHandler(func(app *App, req *Request, res *Response) error {
+		return ErrResponseComplete
+	})
+	h := app.Handler(func(app *App, req *Request, res *Response, pms map[string]string) error {
+		gvar = 7
+		return nil
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if gvar != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
@@ -0,0 +1,79 @@
+package gooh
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ParamConverter func(string) (interface{}, error)
+
+var paramConverters = map[string]ParamConverter{
+	"int":  convertInt,
+	"uuid": convertUUID,
+	"date": convertDate,
+}
+
+type UUID [16]byte
+
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return strings.Join([]string{s[:8], s[8:12], s[12:16], s[16:20], s[20:]}, "-")
+}
+
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.New("invalid uuid: '" + s + "'")
+	}
+
+	b, err := hex.DecodeString(strings.Replace(s, "-", "", 4))
+	if err != nil {
+		return u, errors.New("invalid uuid: '" + s + "'")
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+func convertInt(s string) (interface{}, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func convertUUID(s string) (interface{}, error) {
+	return ParseUUID(s)
+}
+
+func convertDate(s string) (interface{}, error) {
+	return time.Parse("2006-01-02", s)
+}
+
+func (r *Request) Param(name string) (interface{}, bool) {
+	value, ok := r.params[name]
+	return value, ok
+}
+
+func (r *Request) IntParam(name string) (int64, bool) {
+	value, ok := r.params[name].(int64)
+	return value, ok
+}
+
+func (r *Request) UUIDParam(name string) (UUID, bool) {
+	value, ok := r.params[name].(UUID)
+	return value, ok
+}
+
+func (r *Request) DateParam(name string) (time.Time, bool) {
+	value, ok := r.params[name].(time.Time)
+	return value, ok
+}
+
+func (r *Request) setParams(values map[string]interface{}) {
+	if r.params == nil {
+		r.params = make(map[string]interface{})
+	}
+	for name, value := range values {
+		r.params[name] = value
+	}
+}
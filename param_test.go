@@ -0,0 +1,66 @@
+package gooh
+
+import (
+	"testing"
+)
+
+func Test_UUID_String(t *testing.T) {
+	exp := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	u := UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	val := u.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_ParseUUID_Valid(t *testing.T) {
+	exp := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	u, _ := ParseUUID("6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	val := u.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_ParseUUID_Invalid(t *testing.T) {
+	exp := "invalid uuid: '6ba7b810-9dad-11d1-80b4-00c04fd430cz'"
+	_, err := ParseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430cz")
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_ParseUUID_Format(t *testing.T) {
+	exp := "invalid uuid: '6ba7b8109dad11d180b400c04fd430c8'"
+	_, err := ParseUUID("6ba7b8109dad11d180b400c04fd430c8")
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Request_IntParam_Missing(t *testing.T) {
+	exp := false
+	req := new(Request)
+	_, val := req.IntParam("id")
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Request_IntParam_WrongType(t *testing.T) {
+	exp := false
+	req := new(Request)
+	req.setParams(map[string]interface{}{"id": "7"})
+	_, val := req.IntParam("id")
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
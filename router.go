@@ -1,12 +1,22 @@
 package gooh
 
 import (
+	"net"
+	"net/http"
+	"net/url"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 )
 
+var methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
 type RouteHandler func(*App, *Request, *Response, map[string]string) error
 
+type RouteMiddlewareHandler func(*App, *Request, *Response, map[string]string) error
+
 type Route struct {
 	Version *Version
 	Method  string
@@ -19,229 +29,1935 @@ func (r Route) String() string {
 		v = r.Version.String()
 	}
 
-	return strings.Trim(v+" "+strings.ToUpper(r.Method)+" "+r.Path, " ")
+	return strings.Trim(v+" "+r.Method+" "+r.Path, " ")
 }
 
 func getPathFragments(p string) []string {
 	return strings.Split(strings.Trim(p, "/"), "/")
 }
 
+func getRouteFragments(method string, path string) []string {
+	fragments := getPathFragments("/" + method + path)
+	if hasTrailingSlash(path) {
+		fragments = append(fragments, "")
+	}
+	return fragments
+}
+
+func getEscapedPath(u *url.URL) string {
+	if len(u.RawPath) > 0 {
+		if p, err := url.PathUnescape(u.RawPath); err != nil || p == u.Path {
+			return u.RawPath
+		}
+	}
+	return u.EscapedPath()
+}
+
+func unescapeFragments(path string, f []string) ([]string, error) {
+	if !strings.Contains(path, "%") {
+		return f, nil
+	}
+
+	fragments := make([]string, len(f))
+	for i, fragment := range f {
+		unescaped, err := url.PathUnescape(fragment)
+		if err != nil {
+			return nil, &InvalidEncodingError{"invalid encoding: '" + fragment + "' in path: '" + path + "'"}
+		}
+		fragments[i] = unescaped
+	}
+	return fragments, nil
+}
+
+func hasTrailingSlash(path string) bool {
+	return len(path) > 1 && strings.HasSuffix(path, "/")
+}
+
+func toggleTrailingSlash(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path + "/"
+}
+
+func cleanPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if s, err := url.PathUnescape(segment); err == nil && (s == "." || s == "..") {
+			segments[i] = s
+		}
+	}
+	p = strings.Join(segments, "/")
+
+	c := path.Clean("/" + p)
+	if hasTrailingSlash(p) && c != "/" {
+		c += "/"
+	}
+	return c
+}
+
 type node struct {
-	path     string
-	param    string
-	pattern  string
-	handler  *RouteHandler
-	children map[string]*node
-}
-
-func (n *node) addRouteHandler(r *string, f []string, p *map[string]bool, h *RouteHandler) {
-	path := f[0]
-	key := path
-	var pattern string
-	var param string
-
-	if len(path) > 1 && strings.HasPrefix(path, ":") {
-		key = "/"
-		fIndex, lIndex := strings.Index(path, "{"), strings.LastIndex(path, "}")
+	path      string
+	param     string
+	pattern   string
+	regex     *regexp.Regexp
+	kind      string
+	converter ParamConverter
+	fields    []*node
+	route     string
+	handler   *RouteHandler
+	variants  []*routeVariant
+	children  map[string]*node
+	params    []*node
+	wildcard  *node
+}
+
+func parseFragment(r *string, f string, last bool) (*node, error) {
+	n := &node{path: f}
+
+	fields, pattern, err := parseTemplate(r, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		n.param = f
+		n.pattern = pattern
+		n.regex = regexp.MustCompile("^" + pattern + "$")
+		n.fields = fields
+		return n, nil
+	}
+
+	if len(n.path) > 1 && strings.HasPrefix(n.path, ":") {
+		fIndex, lIndex := strings.Index(n.path, "{"), strings.LastIndex(n.path, "}")
 		product := fIndex * lIndex
 		switch {
 		case product > 1:
-			pattern = path[fIndex+1 : lIndex]
-			if _, err := regexp.MatchString(pattern, ""); err != nil {
-				panic(err)
+			n.pattern = n.path[fIndex+1 : lIndex]
+			if _, err := regexp.Compile(n.pattern); err != nil {
+				return nil, &InvalidPatternError{err.Error(), *r}
 			}
-			param = path[1:fIndex]
-			path = path[:fIndex]
+			n.regex = regexp.MustCompile("^(?:" + n.pattern + ")$")
+			n.param = n.path[1:fIndex]
+			n.path = n.path[:fIndex]
 		case product == 1:
-			param = path[1:]
+			n.param = n.path[1:]
 		case product < 0:
-			panic("missing regex delimiter '{'' or '}' in: '" + path + "' for route: '" + (*r) + "'")
+			return nil, &InvalidPatternError{"missing regex delimiter '{'' or '}' in: '" + n.path + "' for route: '" + (*r) + "'", *r}
 		}
+
+		if tIndex := strings.Index(n.param, "<"); tIndex >= 0 || strings.HasSuffix(n.param, ">") {
+			if tIndex < 0 || !strings.HasSuffix(n.param, ">") {
+				return nil, &InvalidPatternError{"missing type delimiter '<' or '>' in: '" + f + "' for route: '" + (*r) + "'", *r}
+			}
+			n.kind = n.param[tIndex+1 : len(n.param)-1]
+			n.param = n.param[:tIndex]
+			n.path = ":" + n.param
+		}
+	}
+
+	if len(n.path) > 1 && strings.HasPrefix(n.path, "*") {
+		if !last {
+			return nil, &InvalidPatternError{"wildcard: '" + n.path + "' must be the last segment for route: '" + (*r) + "'", *r}
+		}
+		n.param = n.path[1:]
 	}
 
-	if n.children == nil {
-		n.children = make(map[string]*node)
+	return n, nil
+}
+
+func parseTemplate(r *string, f string) ([]*node, string, error) {
+	if strings.HasPrefix(f, "*") {
+		return nil, "", nil
 	}
 
-	if len(param) > 0 {
-		if (*p)[param] == true {
-			panic("overwriting parameter: '" + param + "' for route: '" + (*r) + "'")
+	fields := []*node{}
+	pattern := ""
+	literal := false
+	for i := 0; i < len(f); {
+		if !isTemplateParam(f, i) {
+			j := i + 1
+			for j < len(f) && !isTemplateParam(f, j) {
+				j++
+			}
+			pattern += regexp.QuoteMeta(f[i:j])
+			literal = true
+			i = j
+			continue
+		}
+
+		j := i + 1
+		for j < len(f) && isParamChar(f[j]) {
+			j++
+		}
+		field := &node{param: f[i+1 : j]}
+
+		if j < len(f) && f[j] == '<' {
+			k := strings.IndexByte(f[j:], '>')
+			if k < 0 {
+				return nil, "", &InvalidPatternError{"missing type delimiter '<' or '>' in: '" + f + "' for route: '" + (*r) + "'", *r}
+			}
+			field.kind = f[j+1 : j+k]
+			j += k + 1
+		}
+
+		if j < len(f) && f[j] == '{' {
+			depth, k := 0, j
+			for ; k < len(f); k++ {
+				if f[k] == '{' {
+					depth++
+				} else if f[k] == '}' {
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			if k == len(f) {
+				return nil, "", &InvalidPatternError{"missing regex delimiter '{'' or '}' in: '" + f + "' for route: '" + (*r) + "'", *r}
+			}
+			field.pattern = f[j+1 : k]
+			if _, err := regexp.Compile(field.pattern); err != nil {
+				return nil, "", &InvalidPatternError{err.Error(), *r}
+			}
+			field.regex = regexp.MustCompile("^(?:" + field.pattern + ")$")
+			j = k + 1
+		}
+
+		field.path = f[i:j]
+		if len(field.pattern) > 0 {
+			pattern += "(" + field.pattern + ")"
+		} else {
+			pattern += "(.+)"
 		}
-		(*p)[param] = true
+		fields = append(fields, field)
+		i = j
 	}
 
-	child := n.children[key]
-	if child == nil {
-		child = new(node)
-		child.path = path
-		child.param = param
-		child.pattern = pattern
-		n.children[key] = child
+	if len(fields) == 0 || (len(fields) == 1 && (!literal || (strings.HasPrefix(f, ":") && fields[0].regex == nil && len(fields[0].kind) == 0))) {
+		return nil, "", nil
 	}
+	return fields, pattern, nil
+}
 
-	if len(f) == 1 {
-		if child.handler != nil {
-			panic("handler already exists for route: '" + (*r) + "'")
+func isTemplateParam(f string, i int) bool {
+	return f[i] == ':' && i+1 < len(f) && isParamChar(f[i+1])
+}
+
+func isParamChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func (n *node) isWildcard() bool {
+	return len(n.param) > 0 && strings.HasPrefix(n.path, "*")
+}
+
+func getOptionalPaths(path string) []string {
+	paths := []string{""}
+	for _, fragment := range getPathFragments(path) {
+		if len(fragment) == 0 {
+			continue
 		}
-		child.handler = h
-		return
+
+		optional := isOptionalFragment(fragment)
+		fragment = strings.TrimSuffix(fragment, "?")
+
+		expanded := []string{}
+		for _, p := range paths {
+			expanded = append(expanded, p+"/"+fragment)
+			if optional {
+				expanded = append(expanded, p)
+			}
+		}
+		paths = expanded
 	}
 
-	child.addRouteHandler(r, f[1:], p, h)
+	if hasTrailingSlash(path) {
+		for i, p := range paths {
+			if len(p) > 0 {
+				paths[i] = p + "/"
+			}
+		}
+	}
+	return paths
 }
 
-func (n *node) buildRoutes(p string, r *[]string) {
-	if len(n.children) == 0 {
-		if n.handler != nil {
-			*r = append(*r, p)
+func isOptionalFragment(f string) bool {
+	return len(f) > 1 && strings.HasSuffix(f, "?")
+}
+
+func parseRoute(r *string, f []string, cv map[string]ParamConverter) ([]*node, error) {
+	nodes := []*node{}
+	params := make(map[string]bool)
+	for i, fragment := range f {
+		n, err := parseFragment(r, fragment, i == len(f)-1)
+		if err != nil {
+			return nil, err
 		}
-		return
+		if err := resolveParams(r, n, params, cv); err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func resolveParams(r *string, n *node, params map[string]bool, cv map[string]ParamConverter) error {
+	fields := n.fields
+	if len(fields) == 0 && len(n.param) > 0 {
+		fields = []*node{n}
 	}
+	for _, field := range fields {
+		if len(field.kind) > 0 {
+			if field.converter = cv[field.kind]; field.converter == nil {
+				return &InvalidPatternError{"unknown parameter type: '" + field.kind + "' for route: '" + (*r) + "'", *r}
+			}
+		}
 
-	for _, child := range n.children {
-		path := p + "/" + child.String()
-		if child.handler != nil && len(child.children) > 0 {
-			*r = append(*r, path)
+		if params[field.param] {
+			return &InvalidPatternError{"overwriting parameter: '" + field.param + "' for route: '" + (*r) + "'", *r}
 		}
-		child.buildRoutes(path, r)
+		params[field.param] = true
 	}
+	return nil
 }
 
-func (n node) String() string {
-	pattern := n.pattern
-	if len(pattern) > 0 {
-		pattern = "{" + pattern + "}"
+func getRouteShape(f []*node) string {
+	shape := []string{}
+	for _, n := range f {
+		path := n.path
+		switch {
+		case n.isWildcard():
+			path = "*"
+		case len(n.fields) > 0:
+			kinds := []string{}
+			for _, field := range n.fields {
+				kinds = append(kinds, field.kind)
+			}
+			path = ":<" + strings.Join(kinds, ",") + ">{" + n.pattern + "}"
+		case len(n.param) > 0:
+			path = ":<" + n.kind + ">{" + n.pattern + "}"
+		}
+		shape = append(shape, path)
 	}
-	return n.path + pattern
+	return strings.Join(shape, "/")
 }
 
-type Router struct {
-	trees map[string]*node
+type routeVariant struct {
+	constraints *RouteConstraints
+	handler     *RouteHandler
 }
 
-func (r *Router) addRouteHandler(method string, path string, v *Version, h *RouteHandler) {
-	if r.trees == nil {
-		r.trees = make(map[string]*node)
+func (n *node) getChild(fragment *node) *node {
+	switch {
+	case fragment.isWildcard():
+		return n.wildcard
+	case len(fragment.param) > 0:
+		for _, c := range n.params {
+			if c.param == fragment.param && c.pattern == fragment.pattern && c.kind == fragment.kind {
+				return c
+			}
+		}
+		return nil
+	default:
+		return n.children[fragment.path]
 	}
+}
 
-	if !strings.HasPrefix(path, "/") {
-		path = "/" + path
+func (n *node) setChild(fragment *node, child *node) {
+	switch {
+	case fragment.isWildcard():
+		n.wildcard = child
+	case len(fragment.param) > 0:
+		for i, c := range n.params {
+			if c.param == fragment.param && c.pattern == fragment.pattern && c.kind == fragment.kind {
+				if child == nil {
+					n.params = append(n.params[:i:i], n.params[i+1:]...)
+				} else {
+					n.params[i] = child
+				}
+				return
+			}
+		}
+	default:
+		if child == nil {
+			delete(n.children, fragment.path)
+		} else {
+			n.children[fragment.path] = child
+		}
 	}
-	path = strings.TrimSuffix(path, "/")
+}
 
-	root := r.trees[v.String()]
-	if root == nil {
-		root = new(node)
-		root.path = v.String()
-		root.children = make(map[string]*node)
-		r.trees[v.String()] = root
+func (n *node) clone() *node {
+	c := *n
+	if n.children != nil {
+		c.children = make(map[string]*node, len(n.children))
+		for path, child := range n.children {
+			c.children[path] = child
+		}
 	}
+	c.params = append([]*node(nil), n.params...)
+	c.variants = append([]*routeVariant(nil), n.variants...)
+	return &c
+}
 
-	p := make(map[string]bool)
-	root.addRouteHandler(&path, getPathFragments(strings.Join([]string{"/", strings.ToUpper(method), path}, "")), &p, h)
+func (n *node) isEmpty() bool {
+	return !n.hasHandler() && len(n.children) == 0 && len(n.params) == 0 && n.wildcard == nil
 }
 
-func (r *Router) getRouteHandler(method string, path string, v *Version) (*RouteHandler, map[string]string, error) {
-	var rootKey string
-	if v != nil {
-		rootKey = v.String()
-	}
-	root := r.trees[rootKey]
-	if root == nil {
-		return nil, nil, ErrRouteNotFound
+func (n *node) getRoute(f []*node) *node {
+	child := n.getChild(f[0])
+	if child == nil || len(f) == 1 {
+		return child
 	}
+	return child.getRoute(f[1:])
+}
 
-	params := make(map[string]string)
-	fragments := getPathFragments("/" + method + strings.TrimSuffix(path, "/"))
-	var n *node
-	for _, f := range fragments {
-		n = root.children[f]
-
-		if n == nil {
-			n = root.children["/"]
-			if n != nil {
-				matched := len(n.pattern) == 0
-				var err error
-
-				if !matched {
-					matched, err = regexp.MatchString(n.pattern, f)
-					if err != nil {
-						return nil, nil, err
-					}
-				}
+func (n *node) addRouteHandler(r *string, f []*node, h *RouteHandler, c *RouteConstraints) (*node, error) {
+	child := n.getChild(f[0])
+	if child != nil {
+		child = child.clone()
+		n.setChild(f[0], child)
+	} else {
+		child = f[0]
 
-				if matched {
-					params[n.param] = f
-				} else {
-					n = nil
+		switch {
+		case child.isWildcard():
+			n.wildcard = child
+		case len(child.param) > 0:
+			i := len(n.params)
+			for j, c := range n.params {
+				if (len(child.pattern) > 0 || len(child.kind) > 0) && len(c.pattern) == 0 && len(c.kind) == 0 {
+					i = j
+					break
 				}
 			}
+			n.params = append(n.params, nil)
+			copy(n.params[i+1:], n.params[i:])
+			n.params[i] = child
+		default:
+			if n.children == nil {
+				n.children = make(map[string]*node)
+			}
+			n.children[child.path] = child
 		}
+	}
 
-		if n != nil {
-			root = n
-		} else {
-			break
+	if len(f) == 1 {
+		if c != nil {
+			if child.hasVariant(c) {
+				return nil, &DuplicateRouteError{"handler already exists for route: '" + (*r) + "'", *r}
+			}
+			child.variants = append(child.variants, &routeVariant{c, h})
+			return child, nil
+		}
+		if child.handler != nil {
+			return nil, &DuplicateRouteError{"handler already exists for route: '" + (*r) + "'", *r}
 		}
+		child.handler = h
+		return child, nil
+	}
+
+	return child.addRouteHandler(r, f[1:], h, c)
+}
+
+func (n *node) removeRouteHandler(f []*node) bool {
+	child := n.getChild(f[0])
+	if child == nil {
+		return false
 	}
 
-	if n == nil || n.handler == nil {
-		return nil, nil, ErrRouteNotFound
+	child = child.clone()
+	if len(f) == 1 {
+		if !child.hasHandler() {
+			return false
+		}
+		child.handler = nil
+		child.variants = nil
+		child.route = ""
+	} else if !child.removeRouteHandler(f[1:]) {
+		return false
 	}
 
-	return n.handler, params, nil
+	if child.isEmpty() {
+		child = nil
+	}
+	n.setChild(f[0], child)
+	return true
 }
 
-func (r *Router) AddRouteHandler(method string, path string, v Version, h RouteHandler) {
-	if h != nil {
-		r.addRouteHandler(method, path, &v, &h)
+func (n *node) replaceRouteHandler(f []*node, h *RouteHandler, c *RouteConstraints) bool {
+	child := n.getChild(f[0])
+	if child == nil {
+		return false
+	}
+
+	child = child.clone()
+	if len(f) > 1 {
+		if !child.replaceRouteHandler(f[1:], h, c) {
+			return false
+		}
+	} else if c == nil {
+		if child.handler == nil {
+			return false
+		}
+		child.handler = h
+	} else {
+		i := child.getVariant(c)
+		if i < 0 {
+			return false
+		}
+		child.variants[i] = &routeVariant{c, h}
 	}
+
+	n.setChild(f[0], child)
+	return true
 }
 
-func (r *Router) GET(path string, v Version, h RouteHandler) {
-	r.addRouteHandler("GET", path, &v, &h)
+func (n *node) hasHandler() bool {
+	return n.handler != nil || len(n.variants) > 0
 }
 
-func (r *Router) POST(path string, v Version, h RouteHandler) {
-	r.addRouteHandler("POST", path, &v, &h)
+func (n *node) hasVariant(c *RouteConstraints) bool {
+	return n.getVariant(c) >= 0
 }
 
-func (r *Router) PUT(path string, v Version, h RouteHandler) {
-	r.addRouteHandler("PUT", path, &v, &h)
+func (n *node) getVariant(c *RouteConstraints) int {
+	for i, variant := range n.variants {
+		if variant.constraints.equal(c) {
+			return i
+		}
+	}
+	return -1
 }
 
-func (r *Router) DELETE(path string, v Version, h RouteHandler) {
-	r.addRouteHandler("DELETE", path, &v, &h)
+func (n *node) accepts(req *http.Request) bool {
+	if req == nil || len(n.variants) == 0 {
+		return n.hasHandler()
+	}
+
+	_, err := n.getHandler(req)
+	return err == nil
 }
 
-func (r *Router) HEAD(path string, v Version, h RouteHandler) {
-	r.addRouteHandler("HEAD", path, &v, &h)
+func (n *node) getHandler(req *http.Request) (*RouteHandler, error) {
+	var err error = ErrRouteNotFound
+	for _, variant := range n.variants {
+		e := variant.constraints.check(req)
+		if e == nil {
+			return variant.handler, nil
+		}
+		if err == ErrRouteNotFound {
+			err = e
+		}
+	}
+
+	if n.handler != nil {
+		return n.handler, nil
+	}
+	return nil, err
 }
 
-func (r *Router) GetMiddlewareHandler() MiddlewareHandler {
-	return func(app *App, req *Request, res *Response) error {
-		if h, p, err := r.getRouteHandler(strings.ToUpper(req.Method), req.URL.Path, req.ApiVersion); err != nil {
-			return err
-		} else {
-			return (*h)(app, req, res, p)
+func (n *node) convert(s string) (interface{}, bool) {
+	if n.regex != nil && !n.regex.MatchString(s) {
+		return nil, false
+	}
+	if n.converter == nil {
+		return nil, true
+	}
+
+	value, err := n.converter(s)
+	return value, err == nil
+}
+
+func (n *node) capture(s string) ([]string, []interface{}, bool) {
+	groups := n.regex.FindStringSubmatch(s)
+	if groups == nil {
+		return nil, nil, false
+	}
+
+	captured := make([]string, len(n.fields))
+	values := make([]interface{}, len(n.fields))
+	i := 1
+	for j, field := range n.fields {
+		var ok bool
+		captured[j] = groups[i]
+		if values[j], ok = field.convert(groups[i]); !ok {
+			return nil, nil, false
 		}
+		if field.regex != nil {
+			i += field.regex.NumSubexp()
+		}
+		i++
 	}
+	return captured, values, true
 }
 
-func (r *Router) GetRoutes() []*Route {
-	routes := []*Route{}
-	for _, node := range r.trees {
-		version := NewVersion(node.String())
+func (n *node) matchTemplate(f []string, p map[string]string, v map[string]interface{}, req *http.Request) *node {
+	captured, values, ok := n.capture(f[0])
+	if !ok {
+		return nil
+	}
 
-		for _, child := range node.children {
-			method := child.String()
+	m := n.match(f[1:], p, v, req)
+	if m == nil {
+		return nil
+	}
 
-			paths := []string{}
-			child.buildRoutes("", &paths)
-			for _, path := range paths {
-				routes = append(routes, &Route{version, method, path})
+	for j, field := range n.fields {
+		p[field.param] = captured[j]
+		if v != nil && field.converter != nil {
+			v[field.param] = values[j]
+		}
+	}
+	return m
+}
+
+func (n *node) match(f []string, p map[string]string, v map[string]interface{}, req *http.Request) *node {
+	if len(f) == 0 {
+		if !n.accepts(req) && n.wildcard != nil && n.wildcard.accepts(req) {
+			p[n.wildcard.param] = ""
+			return n.wildcard
+		}
+		if !n.accepts(req) {
+			return nil
+		}
+		return n
+	}
+
+	if child := n.children[f[0]]; child != nil {
+		if m := child.match(f[1:], p, v, req); m != nil {
+			return m
+		}
+	}
+
+	for _, child := range n.params {
+		if len(f[0]) == 0 {
+			break
+		}
+
+		if len(child.fields) > 0 {
+			if m := child.matchTemplate(f, p, v, req); m != nil {
+				return m
 			}
+			continue
+		}
+
+		value, ok := child.convert(f[0])
+		if !ok {
+			continue
+		}
+
+		if m := child.match(f[1:], p, v, req); m != nil {
+			p[child.param] = f[0]
+			if v != nil && child.converter != nil {
+				v[child.param] = value
+			}
+			return m
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.accepts(req) {
+		p[n.wildcard.param] = strings.Join(f, "/")
+		return n.wildcard
+	}
+
+	return nil
+}
+
+func (n *node) matchFold(f []string, c []string, p map[string]string, v map[string]interface{}, req *http.Request) *node {
+	if len(f) == 0 {
+		return n.match(f, p, v, req)
+	}
+
+	i := len(c) - len(f)
+	if child := n.children[f[0]]; child != nil {
+		if m := child.matchFold(f[1:], c, p, v, req); m != nil {
+			c[i] = f[0]
+			return m
+		}
+	}
+	for _, key := range getSortedKeys(n.children) {
+		if key != f[0] && strings.EqualFold(key, f[0]) {
+			if m := n.children[key].matchFold(f[1:], c, p, v, req); m != nil {
+				c[i] = key
+				return m
+			}
+		}
+	}
+
+	for _, child := range n.params {
+		if len(f[0]) == 0 {
+			break
+		}
+
+		ok := false
+		if len(child.fields) > 0 {
+			_, _, ok = child.capture(f[0])
+		} else {
+			_, ok = child.convert(f[0])
+		}
+		if !ok {
+			continue
+		}
+
+		if m := child.matchFold(f[1:], c, p, v, req); m != nil {
+			c[i] = f[0]
+			return m
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.accepts(req) {
+		copy(c[i:], f)
+		return n.wildcard
+	}
+
+	return nil
+}
+
+func (n *node) buildRoutes(p string, r *[]string) {
+	if n.hasHandler() {
+		switch {
+		case len(n.route) > 0:
+			if !containsString(*r, n.route) {
+				*r = append(*r, n.route)
+			}
+		case len(p) == 0:
+			*r = append(*r, "/")
+		default:
+			*r = append(*r, p)
+		}
+	}
+
+	children := []*node{}
+	for _, key := range getSortedKeys(n.children) {
+		children = append(children, n.children[key])
+	}
+	children = append(children, n.params...)
+	if n.wildcard != nil {
+		children = append(children, n.wildcard)
+	}
+
+	for _, child := range children {
+		child.buildRoutes(p+"/"+child.String(), r)
+	}
+}
+
+func (n node) String() string {
+	if len(n.fields) > 0 {
+		return n.path
+	}
+
+	kind := n.kind
+	if len(kind) > 0 {
+		kind = "<" + kind + ">"
+	}
+	pattern := n.pattern
+	if len(pattern) > 0 {
+		pattern = "{" + pattern + "}"
+	}
+	return n.path + kind + pattern
+}
+
+type Router struct {
+	table       atomic.Value
+	parent      *Router
+	prefix      string
+	version     *Version
+	middlewares []*RouteMiddlewareHandler
+	constraints *RouteConstraints
+	host        string
+	policy      atomic.Value
+}
+
+type TrailingSlashPolicy int
+
+const (
+	LenientTrailingSlash TrailingSlashPolicy = iota
+	RedirectTrailingSlash
+	StrictTrailingSlash
+)
+
+type CasePolicy int
+
+const (
+	StrictCase CasePolicy = iota
+	LenientCase
+	RedirectCase
+)
+
+type PathPolicy struct {
+	TrailingSlash TrailingSlashPolicy
+	Case          CasePolicy
+	CleanPath     bool
+	RedirectCode  int
+}
+
+type routeTable struct {
+	trees      map[string]*node
+	shapes     map[string]string
+	names      map[string]map[string]*Route
+	mounts     *node
+	mounted    []*mountPoint
+	hosts      []*hostTable
+	converters map[string]ParamConverter
+}
+
+func (t *routeTable) clone() *routeTable {
+	c := &routeTable{
+		trees:      make(map[string]*node, len(t.trees)),
+		shapes:     make(map[string]string, len(t.shapes)),
+		names:      make(map[string]map[string]*Route, len(t.names)),
+		mounts:     t.mounts,
+		mounted:    append([]*mountPoint(nil), t.mounted...),
+		hosts:      append([]*hostTable(nil), t.hosts...),
+		converters: t.converters,
+	}
+	for v, root := range t.trees {
+		c.trees[v] = root
+	}
+	for shape, path := range t.shapes {
+		c.shapes[shape] = path
+	}
+	for name, routes := range t.names {
+		c.names[name] = routes
+	}
+	return c
+}
+
+type hostTable struct {
+	pattern string
+	labels  []*node
+	table   *routeTable
+}
+
+func newHostTable(pattern string, cv map[string]ParamConverter) (*hostTable, error) {
+	h := &hostTable{pattern: strings.ToLower(strings.TrimSuffix(pattern, "."))}
+	params := make(map[string]bool)
+	for _, label := range strings.Split(h.pattern, ".") {
+		n, err := parseFragment(&pattern, label, false)
+		if err == nil {
+			err = resolveParams(&pattern, n, params, cv)
+		}
+		if err != nil {
+			return nil, err
+		}
+		h.labels = append(h.labels, n)
+	}
+	return h, nil
+}
+
+func (h *hostTable) hasParams() bool {
+	for _, label := range h.labels {
+		if len(label.param) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *hostTable) match(host string) (map[string]string, map[string]interface{}, bool) {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), ".")
+	if len(labels) != len(h.labels) {
+		return nil, nil, false
+	}
+
+	params := make(map[string]string)
+	values := make(map[string]interface{})
+	for i, label := range h.labels {
+		switch {
+		case len(label.param) == 0:
+			if label.path != labels[i] {
+				return nil, nil, false
+			}
+		case len(label.fields) > 0:
+			captured, converted, ok := label.capture(labels[i])
+			if !ok {
+				return nil, nil, false
+			}
+			for j, field := range label.fields {
+				params[field.param] = captured[j]
+				if field.converter != nil {
+					values[field.param] = converted[j]
+				}
+			}
+		default:
+			value, ok := label.convert(labels[i])
+			if !ok {
+				return nil, nil, false
+			}
+			params[label.param] = labels[i]
+			if label.converter != nil {
+				values[label.param] = value
+			}
+		}
+	}
+	return params, values, true
+}
+
+type hostMatch struct {
+	table  *routeTable
+	params map[string]string
+	values map[string]interface{}
+}
+
+func (t *routeTable) matchHosts(host string) []*hostMatch {
+	matched := []*hostMatch{}
+	for _, h := range t.hosts {
+		if p, values, ok := h.match(host); ok {
+			matched = append(matched, &hostMatch{h.table, p, values})
+		}
+	}
+	return matched
+}
+
+func (t *routeTable) getHost(pattern string) *hostTable {
+	for _, host := range t.hosts {
+		if host.pattern == pattern {
+			return host
+		}
+	}
+	return nil
+}
+
+func (t *routeTable) updateHost(pattern string) (*routeTable, error) {
+	for i, host := range t.hosts {
+		if host.pattern == pattern {
+			h := &hostTable{host.pattern, host.labels, host.table.clone()}
+			t.hosts[i] = h
+			return h.table, nil
+		}
+	}
+
+	h, err := newHostTable(pattern, t.getParamConverters())
+	if err != nil {
+		return nil, err
+	}
+	h.table = (&routeTable{converters: t.converters}).clone()
+
+	i := len(t.hosts)
+	for j, host := range t.hosts {
+		if !h.hasParams() && host.hasParams() {
+			i = j
+			break
+		}
+	}
+	t.hosts = append(t.hosts, nil)
+	copy(t.hosts[i+1:], t.hosts[i:])
+	t.hosts[i] = h
+	return h.table, nil
+}
+
+type mountPoint struct {
+	prefix string
+	router *Router
+}
+
+func joinPaths(prefix string, path string) string {
+	p := strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+	if len(p) > 1 && !hasTrailingSlash(path) {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+func getRouteMiddlewareHandlers(m []RouteMiddlewareHandler) []*RouteMiddlewareHandler {
+	handlers := []*RouteMiddlewareHandler{}
+	for _, h := range m {
+		if h != nil {
+			h := h
+			handlers = append(handlers, &h)
+		}
+	}
+	return handlers
+}
+
+func chainRouteHandler(m []*RouteMiddlewareHandler, h *RouteHandler) *RouteHandler {
+	if len(m) == 0 {
+		return h
+	}
+
+	c := RouteHandler(func(app *App, req *Request, res *Response, p map[string]string) error {
+		for _, handler := range m {
+			if err := (*handler)(app, req, res, p); err != nil {
+				return err
+			}
+		}
+		return (*h)(app, req, res, p)
+	})
+	return &c
+}
+
+func isToken(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func getRoutePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if path == "/" {
+		path = ""
+	}
+	return path
+}
+
+func (t *routeTable) addRouteHandler(method string, path string, v *Version, h *RouteHandler, c *RouteConstraints, cv map[string]ParamConverter) (*Route, error) {
+	if !isToken(method) {
+		return nil, &InvalidMethodError{"invalid method: '" + method + "' for route: '" + path + "'", path}
+	}
+
+	path = getRoutePath(path)
+	root := t.trees[v.String()]
+	if root == nil {
+		root = new(node)
+		root.path = v.String()
+		root.children = make(map[string]*node)
+	} else {
+		root = root.clone()
+	}
+
+	routes := [][]*node{}
+	shapes := []string{}
+	for _, expanded := range getOptionalPaths(path) {
+		nodes, err := parseRoute(&path, getRouteFragments(method, expanded), cv)
+		if err != nil {
+			return nil, err
+		}
+
+		shape := v.String() + " " + getRouteShape(nodes)
+		if existing, ok := t.shapes[shape]; ok && existing != path {
+			return nil, &AmbiguousRouteError{"ambiguous route: '" + path + "' conflicts with route: '" + existing + "'", path, existing}
+		}
+		if n := root.getRoute(nodes); n != nil && ((c == nil && n.handler != nil) || (c != nil && n.hasVariant(c))) {
+			return nil, &DuplicateRouteError{"handler already exists for route: '" + path + "'", path}
+		}
+
+		routes = append(routes, nodes)
+		shapes = append(shapes, shape)
+	}
+
+	for i, nodes := range routes {
+		n, err := root.addRouteHandler(&path, nodes, h, c)
+		if err != nil {
+			return nil, err
+		}
+		if len(routes) > 1 {
+			n.route = path
+		}
+		t.shapes[shapes[i]] = path
+	}
+	t.trees[v.String()] = root
+
+	return &Route{v, method, path}, nil
+}
+
+func (t *routeTable) removeRouteHandler(method string, path string, v *Version, cv map[string]ParamConverter) (bool, error) {
+	path = getRoutePath(path)
+	root := t.trees[v.String()]
+	if root == nil {
+		return false, nil
+	}
+
+	paths := getOptionalPaths(path)
+	route := ""
+	if len(paths) > 1 {
+		route = path
+	}
+
+	routes := [][]*node{}
+	for _, expanded := range paths {
+		nodes, err := parseRoute(&path, getRouteFragments(method, expanded), cv)
+		if err != nil {
+			return false, err
+		}
+
+		if n := root.getRoute(nodes); n == nil || !n.hasHandler() || n.route != route {
+			return false, nil
+		}
+		routes = append(routes, nodes)
+	}
+
+	root = root.clone()
+	for _, nodes := range routes {
+		root.removeRouteHandler(nodes)
+
+		shape := v.String() + " " + getRouteShape(nodes)
+		if t.shapes[shape] == path {
+			delete(t.shapes, shape)
+		}
+	}
+	t.trees[v.String()] = root
+
+	return true, nil
+}
+
+func (t *routeTable) replaceRouteHandler(method string, path string, v *Version, h *RouteHandler, c *RouteConstraints, cv map[string]ParamConverter) (bool, error) {
+	path = getRoutePath(path)
+	root := t.trees[v.String()]
+	if root == nil {
+		return false, nil
+	}
+
+	paths := getOptionalPaths(path)
+	route := ""
+	if len(paths) > 1 {
+		route = path
+	}
+
+	routes := [][]*node{}
+	for _, expanded := range paths {
+		nodes, err := parseRoute(&path, getRouteFragments(method, expanded), cv)
+		if err != nil {
+			return false, err
+		}
+
+		n := root.getRoute(nodes)
+		if n == nil || n.route != route || (c == nil && n.handler == nil) || (c != nil && !n.hasVariant(c)) {
+			return false, nil
+		}
+		routes = append(routes, nodes)
+	}
+
+	root = root.clone()
+	for _, nodes := range routes {
+		root.replaceRouteHandler(nodes, h, c)
+	}
+	t.trees[v.String()] = root
+
+	return true, nil
+}
+
+func (t *routeTable) addMount(m *mountPoint, h *RouteHandler) error {
+	m.prefix = joinPaths("/", strings.TrimSuffix(m.prefix, "/"))
+	for _, mounted := range t.mounted {
+		if mounted.prefix == m.prefix {
+			return &DuplicateRouteError{"mount already exists for prefix: '" + m.prefix + "'", m.prefix}
+		}
+	}
+
+	nodes, err := parseRoute(&m.prefix, getPathFragments(m.prefix+"/**"), nil)
+	if err != nil {
+		return err
+	}
+
+	if t.mounts == nil {
+		t.mounts = new(node)
+	} else {
+		t.mounts = t.mounts.clone()
+	}
+	if _, err := t.mounts.addRouteHandler(&m.prefix, nodes, h, nil); err != nil {
+		return err
+	}
+	t.mounted = append(t.mounted, m)
+	return nil
+}
+
+func (t *routeTable) addRouteName(name string, route *Route) error {
+	if existing := t.names[name][route.Version.String()]; existing != nil {
+		return &DuplicateRouteError{"route name: '" + name + "' already exists for route: '" + existing.String() + "'", existing.Path}
+	}
+
+	routes := map[string]*Route{route.Version.String(): route}
+	for v, r := range t.names[name] {
+		routes[v] = r
+	}
+	t.names[name] = routes
+	return nil
+}
+
+func (t *routeTable) removeRouteNames(route *Route) {
+	for name, routes := range t.names {
+		existing := routes[route.Version.String()]
+		if existing == nil || existing.Method != route.Method || existing.Path != route.Path {
+			continue
+		}
+
+		c := make(map[string]*Route)
+		for v, r := range routes {
+			if r != existing {
+				c[v] = r
+			}
+		}
+		if len(c) == 0 {
+			delete(t.names, name)
+		} else {
+			t.names[name] = c
+		}
+	}
+}
+
+func (r *Router) getRouteTable() *routeTable {
+	if r.parent != nil {
+		t := r.parent.getRouteTable()
+		if len(r.host) == 0 {
+			return t
+		}
+		if h := t.getHost(r.host); h != nil {
+			return h.table
+		}
+		return &routeTable{converters: t.converters}
+	}
+
+	if t, ok := r.table.Load().(*routeTable); ok {
+		return t
+	}
+	return new(routeTable)
+}
+
+func (r *Router) updateRouteTable(fn func(t *routeTable) error) error {
+	if r.parent != nil && len(r.host) > 0 {
+		return r.parent.updateRouteTable(func(t *routeTable) error {
+			h, err := t.updateHost(r.host)
+			if err != nil {
+				return err
+			}
+			return fn(h)
+		})
+	}
+	if r.parent != nil {
+		return r.parent.updateRouteTable(fn)
+	}
+
+	for {
+		old := r.table.Load()
+		t := new(routeTable)
+		if o, ok := old.(*routeTable); ok {
+			t = o
+		}
+
+		t = t.clone()
+		if err := fn(t); err != nil {
+			return err
+		}
+		if r.table.CompareAndSwap(old, t) {
+			return nil
+		}
+	}
+}
+
+func (r *Router) resolveRoute(path string, v *Version, h *RouteHandler, c *RouteConstraints) (string, *Version, *RouteHandler, *RouteConstraints) {
+	if r.parent == nil {
+		return path, v, h, c
+	}
+
+	if len(v.String()) == 0 {
+		v = r.version
+	}
+	return r.parent.resolveRoute(joinPaths(r.prefix, path), v, chainRouteHandler(r.middlewares, h), mergeRouteConstraints(r.constraints, c))
+}
+
+func (r *Router) addRouteHandler(method string, path string, v *Version, h *RouteHandler, c *RouteConstraints) (*Route, error) {
+	path, v, h, c = r.resolveRoute(path, v, h, c)
+
+	var route *Route
+	err := r.updateRouteTable(func(t *routeTable) (err error) {
+		route, err = t.addRouteHandler(method, path, v, h, c, t.getParamConverters())
+		return err
+	})
+	return route, err
+}
+
+func (r *Router) addMount(m *mountPoint, h *RouteHandler) error {
+	if r.parent != nil && len(r.host) == 0 {
+		m.prefix = joinPaths(r.prefix, m.prefix)
+		return r.parent.addMount(m, chainRouteHandler(r.middlewares, h))
+	}
+
+	return r.updateRouteTable(func(t *routeTable) error {
+		return t.addMount(m, h)
+	})
+}
+
+func (t *routeTable) getParamConverters() map[string]ParamConverter {
+	converters := make(map[string]ParamConverter)
+	for name, c := range paramConverters {
+		converters[name] = c
+	}
+	for name, c := range t.converters {
+		converters[name] = c
+	}
+	return converters
+}
+
+func (r *Router) getParamConverters() map[string]ParamConverter {
+	return r.getRouteTable().getParamConverters()
+}
+
+func (r *Router) getRouteHandler(method string, path string, v *Version, hr *http.Request) (*RouteHandler, map[string]string, map[string]interface{}, error) {
+	return r.getRouteTable().getRouteHandler(method, path, v, hr)
+}
+
+func (t *routeTable) getRouteHandler(method string, path string, v *Version, hr *http.Request) (*RouteHandler, map[string]string, map[string]interface{}, error) {
+	var rootKey string
+	if v != nil {
+		rootKey = v.String()
+	}
+	root := t.trees[rootKey]
+
+	raw := getRouteFragments(method, path)
+	fragments, err := unescapeFragments(path, raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	params := make(map[string]string)
+	values := make(map[string]interface{})
+	if root != nil {
+		if n := root.match(fragments, params, values, hr); n != nil {
+			h, err := n.getHandler(hr)
+			if err == nil {
+				return h, params, values, nil
+			}
+			if err != ErrRouteNotFound {
+				return nil, nil, nil, err
+			}
+		} else if n := root.match(fragments, make(map[string]string), nil, nil); n != nil && hr != nil {
+			if _, err := n.getHandler(hr); err != ErrRouteNotFound {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	if t.mounts != nil {
+		if n := t.mounts.match(raw[1:], params, nil, nil); n != nil {
+			for key, value := range params {
+				if key != "*" {
+					params[key], _ = url.PathUnescape(value)
+				}
+			}
+			return n.handler, params, values, nil
+		}
+	}
+
+	if root != nil {
+		if allowed := getAllowedMethods(root, fragments); len(allowed) > 0 {
+			return nil, nil, nil, &MethodNotAllowedError{"method not allowed", allowed}
+		}
+	}
+
+	return nil, nil, nil, ErrRouteNotFound
+}
+
+func getAllowedMethods(root *node, f []string) []string {
+	methods := []string{}
+	fragments := make([]string, len(f))
+	copy(fragments, f)
+
+	for method := range root.children {
+		if method == f[0] {
+			continue
+		}
+
+		fragments[0] = method
+		if n := root.match(fragments, make(map[string]string), nil, nil); n != nil && n.hasHandler() {
+			methods = append(methods, method)
+		}
+	}
+
+	if len(methods) > 0 {
+		if containsString(methods, "GET") && !containsString(methods, "HEAD") {
+			methods = append(methods, "HEAD")
+		}
+		if !containsString(methods, "OPTIONS") {
+			methods = append(methods, "OPTIONS")
+		}
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+func getSortedKeys(m map[string]*node) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(s []string, v string) bool {
+	for _, value := range s {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (r *Router) TryAddRouteHandler(method string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) error {
+	if h == nil {
+		return nil
+	}
+
+	_, err := r.addRouteHandler(method, path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h), nil)
+	return err
+}
+
+func (r *Router) AddRouteHandler(method string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	if err := r.TryAddRouteHandler(method, path, v, h, m...); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) TryAddNamedRouteHandler(name string, method string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) error {
+	if h == nil {
+		return nil
+	}
+
+	path, rv, rh, rc := r.resolveRoute(path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h), nil)
+	return r.updateRouteTable(func(t *routeTable) error {
+		route, err := t.addRouteHandler(method, path, rv, rh, rc, t.getParamConverters())
+		if err != nil {
+			return err
+		}
+		return t.addRouteName(name, route)
+	})
+}
+
+func (r *Router) AddNamedRouteHandler(name string, method string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	if err := r.TryAddNamedRouteHandler(name, method, path, v, h, m...); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) RemoveRouteHandler(method string, path string, v Version) error {
+	path, rv, _, _ := r.resolveRoute(path, &v, nil, nil)
+	return r.updateRouteTable(func(t *routeTable) error {
+		removed, err := t.removeRouteHandler(method, path, rv, t.getParamConverters())
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return &RouteNotFoundError{"route not found: '" + getRoutePath(path) + "'"}
+		}
+
+		t.removeRouteNames(&Route{rv, method, getRoutePath(path)})
+		return nil
+	})
+}
+
+func (r *Router) ReplaceRouteHandler(method string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) error {
+	if h == nil {
+		return nil
+	}
+
+	path, rv, rh, rc := r.resolveRoute(path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h), nil)
+	return r.updateRouteTable(func(t *routeTable) error {
+		replaced, err := t.replaceRouteHandler(method, path, rv, rh, rc, t.getParamConverters())
+		if err != nil {
+			return err
+		}
+		if !replaced {
+			return &RouteNotFoundError{"route not found: '" + getRoutePath(path) + "'"}
+		}
+		return nil
+	})
+}
+
+func (r *Router) SwapRoutes(o *Router) {
+	if r.parent != nil {
+		r.parent.SwapRoutes(o)
+		return
+	}
+	r.table.Store(o.getRouteTable())
+}
+
+func (r *Router) mustAddRouteHandler(method string, path string, v *Version, h *RouteHandler) {
+	if _, err := r.addRouteHandler(method, path, v, h, nil); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) GET(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("GET", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) POST(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("POST", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) PUT(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("PUT", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) DELETE(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("DELETE", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) HEAD(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("HEAD", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) PATCH(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("PATCH", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) OPTIONS(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.mustAddRouteHandler("OPTIONS", path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h))
+}
+
+func (r *Router) Any(path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	r.Match(methods, path, v, h, m...)
+}
+
+func (r *Router) Match(methods []string, path string, v Version, h RouteHandler, m ...RouteMiddlewareHandler) {
+	path, rv, rh, rc := r.resolveRoute(path, &v, chainRouteHandler(getRouteMiddlewareHandlers(m), &h), nil)
+
+	err := r.updateRouteTable(func(t *routeTable) error {
+		converters := t.getParamConverters()
+		for _, method := range methods {
+			if _, err := t.addRouteHandler(method, path, rv, rh, rc, converters); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) URL(name string, params map[string]string) (string, error) {
+	return r.VersionURL(name, Version{}, params)
+}
+
+func (r *Router) VersionURL(name string, v Version, params map[string]string) (string, error) {
+	if r.parent != nil {
+		return r.parent.VersionURL(name, v, params)
+	}
+
+	t := r.getRouteTable()
+	route := t.names[name][v.String()]
+	if route == nil {
+		return "", &RouteNotFoundError{"route not found: '" + name + "'"}
+	}
+
+	segments := []string{}
+	converters := t.getParamConverters()
+	fragments := getPathFragments(route.Path)
+	for i, fragment := range fragments {
+		optional := isOptionalFragment(fragment)
+		n, _ := parseFragment(&route.Path, strings.TrimSuffix(fragment, "?"), i == len(fragments)-1)
+		if len(n.param) == 0 {
+			segments = append(segments, n.path)
+			continue
+		}
+
+		if optional && !hasURLParams(n, params) {
+			continue
+		}
+
+		if len(n.fields) > 0 {
+			segment, rest := "", n.path
+			for _, field := range n.fields {
+				value, err := getURLParam(route, field, params, converters)
+				if err != nil {
+					return "", err
+				}
+				j := strings.Index(rest, field.path)
+				segment += rest[:j] + url.PathEscape(value)
+				rest = rest[j+len(field.path):]
+			}
+			segments = append(segments, segment+rest)
+			continue
+		}
+
+		value, err := getURLParam(route, n, params, converters)
+		if err != nil {
+			return "", err
+		}
+
+		if n.isWildcard() {
+			for _, s := range strings.Split(strings.Trim(value, "/"), "/") {
+				segments = append(segments, url.PathEscape(s))
+			}
+			continue
+		}
+		segments = append(segments, url.PathEscape(value))
+	}
+
+	u := "/" + strings.TrimSuffix(strings.Join(segments, "/"), "/")
+	if hasTrailingSlash(route.Path) && len(u) > 1 {
+		u += "/"
+	}
+	return u, nil
+}
+
+func hasURLParams(n *node, params map[string]string) bool {
+	fields := n.fields
+	if len(fields) == 0 {
+		fields = []*node{n}
+	}
+
+	for _, field := range fields {
+		if len(params[field.param]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func getURLParam(route *Route, n *node, params map[string]string, converters map[string]ParamConverter) (string, error) {
+	value := params[n.param]
+	if len(value) == 0 && !n.isWildcard() {
+		return "", &InvalidParameterError{"missing parameter: '" + n.param + "' for route: '" + route.Path + "'"}
+	}
+
+	n.converter = converters[n.kind]
+	if _, ok := n.convert(value); !ok {
+		return "", &InvalidParameterError{"invalid value: '" + value + "' for parameter: '" + n.param + "' for route: '" + route.Path + "'"}
+	}
+	return value, nil
+}
+
+func (r *Router) TryHost(pattern string) (*Router, error) {
+	if r.parent != nil {
+		parent, err := r.parent.TryHost(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &Router{parent: parent, prefix: r.prefix, version: r.version, middlewares: r.middlewares, constraints: r.constraints}, nil
+	}
+
+	h, err := newHostTable(pattern, r.getParamConverters())
+	if err != nil {
+		return nil, err
+	}
+
+	router := &Router{parent: r, version: &Version{}, host: h.pattern}
+	if err := router.updateRouteTable(func(t *routeTable) error { return nil }); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+func (r *Router) Host(pattern string) *Router {
+	router, err := r.TryHost(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return router
+}
+
+func (r *Router) Constrain(c RouteConstraints) *Router {
+	return &Router{parent: r, version: &Version{}, constraints: &c}
+}
+
+func (r *Router) SetPathPolicy(p PathPolicy) {
+	if r.parent != nil {
+		r.parent.SetPathPolicy(p)
+		return
+	}
+	r.policy.Store(p)
+}
+
+func (r *Router) getPathPolicy() PathPolicy {
+	if r.parent != nil {
+		return r.parent.getPathPolicy()
+	}
+
+	p, _ := r.policy.Load().(PathPolicy)
+	return p
+}
+
+func (r *Router) AddParamConverter(name string, c ParamConverter) {
+	if r.parent != nil {
+		r.parent.AddParamConverter(name, c)
+		return
+	}
+
+	if len(name) == 0 || c == nil {
+		panic("invalid converter for parameter type: '" + name + "'")
+	}
+
+	r.updateRouteTable(func(t *routeTable) error {
+		converters := map[string]ParamConverter{name: c}
+		for n, converter := range t.converters {
+			if n != name {
+				converters[n] = converter
+			}
+		}
+		t.converters = converters
+
+		for i, host := range t.hosts {
+			table := host.table.clone()
+			table.converters = converters
+			t.hosts[i] = &hostTable{host.pattern, host.labels, table}
+		}
+		return nil
+	})
+}
+
+func (r *Router) Group(prefix string, v Version, m ...RouteMiddlewareHandler) *Router {
+	return &Router{parent: r, prefix: prefix, version: &v, middlewares: getRouteMiddlewareHandlers(m)}
+}
+
+func (r *Router) TryMount(prefix string, h interface{}) error {
+	var c RouteHandler
+	m := &mountPoint{prefix: prefix}
+
+	switch v := h.(type) {
+	case *Router:
+		m.router = v
+		c = func(app *App, req *Request, res *Response, p map[string]string) error {
+			path := "/" + p["*"]
+			delete(p, "*")
+			return v.serveRoute(v.getRouteTable(), app, req, res, path, p)
+		}
+	case http.Handler:
+		c = func(app *App, req *Request, res *Response, p map[string]string) error {
+			hr := req.Request.Clone(req.Request.Context())
+			hr.URL.RawPath = "/" + p["*"]
+			hr.URL.Path, _ = url.PathUnescape(hr.URL.RawPath)
+			for key, value := range p {
+				if key != "*" {
+					hr.SetPathValue(key, value)
+				}
+			}
+			v.ServeHTTP(res, hr)
+			return nil
+		}
+	default:
+		return &InvalidPatternError{"invalid handler mounted on prefix: '" + prefix + "'", prefix}
+	}
+
+	return r.addMount(m, &c)
+}
+
+func (r *Router) Mount(prefix string, h interface{}) {
+	if err := r.TryMount(prefix, h); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) serveRoute(t *routeTable, app *App, req *Request, res *Response, path string, params map[string]string) error {
+	method := req.Method
+
+	hosts := t.matchHosts(req.Host)
+	for _, host := range hosts {
+		if _, _, _, err := host.table.getRouteHandler(method, path, req.ApiVersion, req.Request); err != ErrRouteNotFound {
+			req.setParams(host.values)
+			return r.serveRoute(host.table, app, req, res, path, mergeParams(host.params, params))
+		}
+	}
+	h, p, values, err := t.getRouteHandler(method, path, req.ApiVersion, req.Request)
+	if err == ErrRouteNotFound {
+		if fallback, redirect, ok := r.getFallbackPath(t, hosts, req, method, path); ok {
+			if redirect {
+				return r.redirect(req, res, strings.TrimSuffix(getEscapedPath(req.URL), path)+fallback)
+			}
+			return r.serveRoute(t, app, req, res, fallback, params)
+		}
+	}
+	if err == nil {
+		req.setParams(values)
+		return (*h)(app, req, res, mergeParams(p, params))
+	}
+
+	e, ok := err.(*MethodNotAllowedError)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case method == "HEAD" && containsString(e.Allowed, "GET"):
+		if h, p, values, err = t.getRouteHandler("GET", path, req.ApiVersion, req.Request); err != nil {
+			return err
+		}
+		req.setParams(values)
+		return (*h)(app, req, &Response{ResponseWriter: headResponseWriter{res}}, mergeParams(p, params))
+	case method == "OPTIONS":
+		res.Header().Set("Allow", strings.Join(e.Allowed, ", "))
+		res.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	return err
+}
+
+func (r *Router) getFallbackPath(t *routeTable, hosts []*hostMatch, req *Request, method string, path string) (string, bool, bool) {
+	policy := r.getPathPolicy()
+
+	candidates := []string{path}
+	if policy.TrailingSlash != StrictTrailingSlash && len(strings.Trim(path, "/")) > 0 {
+		toggled := toggleTrailingSlash(path)
+		if hasRoute(t, hosts, req, method, toggled) {
+			return toggled, policy.TrailingSlash == RedirectTrailingSlash, true
+		}
+		candidates = append(candidates, toggled)
+	}
+
+	if policy.Case != StrictCase {
+		for _, candidate := range candidates {
+			if canonical, ok := getCanonicalPath(t, hosts, req, method, candidate); ok {
+				redirect := policy.Case == RedirectCase || (candidate != path && policy.TrailingSlash == RedirectTrailingSlash)
+				return canonical, redirect, true
+			}
+		}
+	}
+
+	return "", false, false
+}
+
+func getCanonicalPath(t *routeTable, hosts []*hostMatch, req *Request, method string, path string) (string, bool) {
+	for _, host := range hosts {
+		if canonical, ok := getCanonicalPath(host.table, nil, req, method, path); ok {
+			return canonical, true
+		}
+	}
+
+	var rootKey string
+	if req.ApiVersion != nil {
+		rootKey = req.ApiVersion.String()
+	}
+
+	raw := getRouteFragments(method, path)
+	fragments, err := unescapeFragments(path, raw)
+	if err != nil {
+		return "", false
+	}
+
+	canonical := make([]string, len(fragments))
+	found := false
+	if root := t.trees[rootKey]; root != nil {
+		if n := root.children[method]; n != nil && n.matchFold(fragments[1:], canonical[1:], make(map[string]string), nil, req.Request) != nil {
+			found = true
+		}
+	}
+	if !found && t.mounts != nil && t.mounts.matchFold(fragments[1:], canonical[1:], make(map[string]string), nil, nil) != nil {
+		found = true
+	}
+	if !found {
+		return "", false
+	}
+
+	for i := 1; i < len(canonical); i++ {
+		if canonical[i] == fragments[i] {
+			canonical[i] = raw[i]
+		} else {
+			canonical[i] = url.PathEscape(canonical[i])
+		}
+	}
+	return "/" + strings.Join(canonical[1:], "/"), true
+}
+
+func hasRoute(t *routeTable, hosts []*hostMatch, req *Request, method string, path string) bool {
+	for _, host := range hosts {
+		if _, _, _, err := host.table.getRouteHandler(method, path, req.ApiVersion, req.Request); err != ErrRouteNotFound {
+			return true
+		}
+	}
+
+	_, _, _, err := t.getRouteHandler(method, path, req.ApiVersion, req.Request)
+	return err != ErrRouteNotFound
+}
+
+func (r *Router) redirect(req *Request, res *Response, path string) error {
+	code := r.getPathPolicy().RedirectCode
+	if code == 0 {
+		code = http.StatusMovedPermanently
+	}
+
+	path = "/" + strings.TrimLeft(path, "/")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = (&url.URL{Path: unescaped, RawPath: path}).EscapedPath()
+	} else {
+		path = (&url.URL{Path: path}).EscapedPath()
+	}
+	if len(req.URL.RawQuery) > 0 {
+		path += "?" + req.URL.RawQuery
+	}
+	http.Redirect(res, req.Request, path, code)
+	return nil
+}
+
+func mergeParams(p map[string]string, params map[string]string) map[string]string {
+	for key, value := range params {
+		if _, ok := p[key]; !ok {
+			p[key] = value
+		}
+	}
+	return p
+}
+
+func (r *Router) GetMiddlewareHandler() MiddlewareHandler {
+	return func(app *App, req *Request, res *Response) error {
+		path := getEscapedPath(req.URL)
+		if r.getPathPolicy().CleanPath {
+			if cleaned := cleanPath(path); cleaned != path {
+				return r.redirect(req, res, cleaned)
+			}
+		}
+		return r.serveRoute(r.getRouteTable(), app, req, res, path, nil)
+	}
+}
+
+func (r *Router) GetRoutes() []*Route {
+	if r.parent != nil {
+		return r.parent.GetRoutes()
+	}
+
+	return r.getRouteTable().getRoutes()
+}
+
+func (t *routeTable) getRoutes() []*Route {
+	routes := []*Route{}
+	for _, v := range getSortedKeys(t.trees) {
+		node := t.trees[v]
+		version := NewVersion(node.String())
+
+		for _, method := range getSortedKeys(node.children) {
+			child := node.children[method]
+
+			paths := []string{}
+			child.buildRoutes("", &paths)
+			for _, path := range paths {
+				routes = append(routes, &Route{version, method, path})
+			}
+
+		}
+	}
+
+	for _, host := range t.hosts {
+		for _, route := range host.table.getRoutes() {
+			routes = append(routes, &Route{route.Version, route.Method, host.pattern + route.Path})
+		}
+	}
+
+	for _, m := range t.mounted {
+		if m.router == nil {
+			routes = append(routes, &Route{&Version{}, "", joinPaths(m.prefix, "/*")})
+			continue
+		}
 
+		for _, route := range m.router.GetRoutes() {
+			routes = append(routes, &Route{route.Version, route.Method, joinPaths(m.prefix, route.Path)})
 		}
 	}
 
@@ -2,9 +2,13 @@ package gooh
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -49,7 +53,7 @@ func Test_Route_String_EmptyVersionAndMethod(t *testing.T) {
 }
 
 func Test_Route_String_LowerMethod(t *testing.T) {
-	exp := "GET /users"
+	exp := "get /users"
 	route := Route{&Version{}, "get", "/users"}
 	val := route.String()
 
@@ -215,7 +219,7 @@ func Test_Router_AddRouteHandler_Version(t *testing.T) {
 }
 
 func Test_Router_AddRouteHandler_WrongMethod(t *testing.T) {
-	exp := "route not found"
+	exp := "method not allowed"
 
 	r := new(Router)
 	r.AddRouteHandler("GET", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
@@ -238,9 +242,174 @@ func Test_Router_AddRouteHandler_WrongMethod(t *testing.T) {
 	}
 }
 
-func Test_Router_AddRouteHandler_LowerMethodRegistered(t *testing.T) {
+func Test_Router_AddRouteHandler_MethodNotAllowed(t *testing.T) {
+	exp := "DELETE,GET,HEAD,OPTIONS"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.AddRouteHandler("DELETE", "/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.AddRouteHandler("POST", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "PUT"
+	req.ApiVersion = &Version{1, 0, 0}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users/7"
+
+	err := mh(nil, req, nil)
+	e, ok := err.(*MethodNotAllowedError)
+	if !ok {
+		t.Fatalf("Expected '%v', got '%v'", exp, err)
+	}
+	val := strings.Join(e.Allowed, ",")
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_MethodNotAllowedWrongPath(t *testing.T) {
+	exp := "route not found"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "POST"
+	req.ApiVersion = &Version{1, 0, 0}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/groups"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_Options(t *testing.T) {
+	exp := "204;DELETE, GET, HEAD, OPTIONS"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.AddRouteHandler("DELETE", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "OPTIONS"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users/7"
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Allow"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_OptionsRegistered(t *testing.T) {
 	exp := "error"
 
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.AddRouteHandler("OPTIONS", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "OPTIONS"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_HeadFallback(t *testing.T) {
+	exp := "200;7;0;"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		res.Header().Set("X-Id", pms["id"])
+		res.Write([]byte("hello"))
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "HEAD"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users/7"
+	rec := httptest.NewRecorder()
+	res := &Response{ResponseWriter: rec}
+
+	mh(nil, req, res)
+	val := fmt.Sprintf("%v;%v;%v;%v", res.Status(), rec.Header().Get("X-Id"), res.Size(), rec.Body.String())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_HeadWithoutGet(t *testing.T) {
+	exp := "method not allowed"
+
+	r := new(Router)
+	r.AddRouteHandler("POST", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "HEAD"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_LowerMethodRegistered(t *testing.T) {
+	exp := "method not allowed"
+
 	r := new(Router)
 	r.AddRouteHandler("get", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
 		return errors.New("error")
@@ -263,7 +432,7 @@ func Test_Router_AddRouteHandler_LowerMethodRegistered(t *testing.T) {
 }
 
 func Test_Router_AddRouteHandler_LowerMethodRequest(t *testing.T) {
-	exp := "error"
+	exp := "method not allowed"
 
 	r := new(Router)
 	r.AddRouteHandler("GET", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
@@ -382,6 +551,35 @@ func Test_Router_AddRouteHandler_NotMatchingRegex(t *testing.T) {
 	}
 }
 
+func Test_Router_AddRouteHandler_RegexAnchored(t *testing.T) {
+	exp := "route not found;route not found;route not found"
+	val := []string{}
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("id:" + pms["id"])
+	})
+	r.AddRouteHandler("GET", "/groups/:name{a|bc}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("name:" + pms["name"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, path := range []string{"/users/abc123", "/users/123abc", "/groups/abc"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = "GET"
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = path
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
 func Test_Router_AddRouteHandler_NestedPath(t *testing.T) {
 	exp := "error"
 
@@ -430,12 +628,12 @@ func Test_Router_AddRouteHandler_Parameters(t *testing.T) {
 	}
 }
 
-func Test_Router_GET_Basic(t *testing.T) {
-	exp := "error"
+func Test_Router_AddRouteHandler_Wildcard(t *testing.T) {
+	exp := "css/site/main.css"
 
 	r := new(Router)
-	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return errors.New("error")
+	r.AddRouteHandler("GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New(pms["filepath"])
 	})
 	mh := r.GetMiddlewareHandler()
 
@@ -444,7 +642,7 @@ func Test_Router_GET_Basic(t *testing.T) {
 	req.Request.Method = "GET"
 	req.ApiVersion = &Version{}
 	req.Request.URL = new(url.URL)
-	req.Request.URL.Path = "/users"
+	req.Request.URL.Path = "/files/css/site/main.css"
 
 	err := mh(nil, req, nil)
 	val := err.Error()
@@ -454,21 +652,21 @@ func Test_Router_GET_Basic(t *testing.T) {
 	}
 }
 
-func Test_Router_POST_Basic(t *testing.T) {
-	exp := "error"
+func Test_Router_AddRouteHandler_WildcardEmpty(t *testing.T) {
+	exp := "filepath:"
 
 	r := new(Router)
-	r.POST("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return errors.New("error")
+	r.AddRouteHandler("GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("filepath:" + pms["filepath"])
 	})
 	mh := r.GetMiddlewareHandler()
 
 	req := new(Request)
 	req.Request = new(http.Request)
-	req.Request.Method = "POST"
+	req.Request.Method = "GET"
 	req.ApiVersion = &Version{}
 	req.Request.URL = new(url.URL)
-	req.Request.URL.Path = "/users"
+	req.Request.URL.Path = "/files/"
 
 	err := mh(nil, req, nil)
 	val := err.Error()
@@ -478,45 +676,76 @@ func Test_Router_POST_Basic(t *testing.T) {
 	}
 }
 
-func Test_Router_PUT_Basic(t *testing.T) {
-	exp := "error"
+func Test_Router_AddRouteHandler_WildcardPrecedence(t *testing.T) {
+	exp := "static;param:7;wildcard:a/b;root"
+	val := []string{}
 
 	r := new(Router)
-	r.PUT("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return errors.New("error")
+	r.AddRouteHandler("GET", "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("root")
+	})
+	r.AddRouteHandler("GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("wildcard:" + pms["filepath"])
+	})
+	r.AddRouteHandler("GET", "/files/:id{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("param:" + pms["id"])
+	})
+	r.AddRouteHandler("GET", "/files/static", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("static")
 	})
 	mh := r.GetMiddlewareHandler()
 
-	req := new(Request)
-	req.Request = new(http.Request)
-	req.Request.Method = "PUT"
-	req.ApiVersion = &Version{}
-	req.Request.URL = new(url.URL)
-	req.Request.URL.Path = "/users"
+	for _, path := range []string{"/files/static", "/files/7", "/files/a/b", "/files"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = "GET"
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = path
 
-	err := mh(nil, req, nil)
-	val := err.Error()
+		val = append(val, mh(nil, req, nil).Error())
+	}
 
-	if val != exp {
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddRouteHandler_WildcardNotLast(t *testing.T) {
+	exp := "wildcard: '*filepath' must be the last segment for route: '/files/*filepath/meta'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/files/*filepath/meta", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
 		t.Errorf("Expected '%v', got '%v'", exp, val)
 	}
 }
 
-func Test_Router_DELETE_Basic(t *testing.T) {
-	exp := "error"
+func Test_Router_AddRouteHandler_BacktrackStatic(t *testing.T) {
+	exp := "posts:me"
 
 	r := new(Router)
-	r.DELETE("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return errors.New("error")
+	r.AddRouteHandler("GET", "/users/me/settings", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("settings")
+	})
+	r.AddRouteHandler("GET", "/users/:id/posts", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("posts:" + pms["id"])
 	})
 	mh := r.GetMiddlewareHandler()
 
 	req := new(Request)
 	req.Request = new(http.Request)
-	req.Request.Method = "DELETE"
+	req.Request.Method = "GET"
 	req.ApiVersion = &Version{}
 	req.Request.URL = new(url.URL)
-	req.Request.URL.Path = "/users"
+	req.Request.URL.Path = "/users/me/posts"
 
 	err := mh(nil, req, nil)
 	val := err.Error()
@@ -526,21 +755,53 @@ func Test_Router_DELETE_Basic(t *testing.T) {
 	}
 }
 
-func Test_Router_HEAD_Basic(t *testing.T) {
-	exp := "error"
+func Test_Router_AddRouteHandler_BacktrackParameters(t *testing.T) {
+	exp := "id:7;slug:my-item;route not found"
+	val := []string{}
 
 	r := new(Router)
-	r.HEAD("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return errors.New("error")
+	r.AddRouteHandler("GET", "/items/:id{[0-9]+}/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("id:" + pms["id"])
+	})
+	r.AddRouteHandler("GET", "/items/:slug{[a-z-]+}/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("slug:" + pms["slug"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, path := range []string{"/items/7/details", "/items/my-item/details", "/items/A/details"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = "GET"
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = path
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddRouteHandler_BacktrackWildcard(t *testing.T) {
+	exp := "wildcard:7/other;id:"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/files/:id/meta", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("meta")
+	})
+	r.AddRouteHandler("GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("wildcard:" + pms["filepath"] + ";id:" + pms["id"])
 	})
 	mh := r.GetMiddlewareHandler()
 
 	req := new(Request)
 	req.Request = new(http.Request)
-	req.Request.Method = "HEAD"
+	req.Request.Method = "GET"
 	req.ApiVersion = &Version{}
 	req.Request.URL = new(url.URL)
-	req.Request.URL.Path = "/users"
+	req.Request.URL.Path = "/files/7/other"
 
 	err := mh(nil, req, nil)
 	val := err.Error()
@@ -550,64 +811,2736 @@ func Test_Router_HEAD_Basic(t *testing.T) {
 	}
 }
 
-func Test_Router_String_Basic(t *testing.T) {
-	exp := "GET /users"
+func Test_Router_AddRouteHandler_ParametersSpecificity(t *testing.T) {
+	exp := "id:7;slug:my-item;name:A"
+	val := []string{}
 
 	r := new(Router)
-	r.AddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return nil
+	r.AddRouteHandler("GET", "/items/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("name:" + pms["name"])
 	})
-	val := r.String()
+	r.AddRouteHandler("GET", "/items/:id{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("id:" + pms["id"])
+	})
+	r.AddRouteHandler("GET", "/items/:slug{[a-z-]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("slug:" + pms["slug"])
+	})
+	mh := r.GetMiddlewareHandler()
 
-	if val != exp {
-		t.Errorf("Expected '%v', got '%v'", exp, val)
+	for _, path := range []string{"/items/7", "/items/my-item", "/items/A"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = "GET"
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = path
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
 	}
 }
 
-func Test_Router_String_Version(t *testing.T) {
-	exp := "v1 GET /users"
+func Test_Router_AddRouteHandler_ParametersDifferentNames(t *testing.T) {
+	exp := "posts:7;friends:8"
+	val := []string{}
 
 	r := new(Router)
-	r.AddRouteHandler("GET", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return nil
+	r.AddRouteHandler("GET", "/users/:id/posts", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("posts:" + pms["id"])
 	})
-	val := r.String()
+	r.AddRouteHandler("GET", "/users/:uid/friends", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("friends:" + pms["uid"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, path := range []string{"/users/7/posts", "/users/8/friends"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = "GET"
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = path
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddRouteHandler_ParametersAmbiguous(t *testing.T) {
+	exp := "ambiguous route: '/items/:slug{[0-9]+}/details' conflicts with route: '/items/:id{[0-9]+}/details'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/items/:id{[0-9]+}/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.AddRouteHandler("GET", "/items/:slug{[0-9]+}/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_ParametersAmbiguousOtherMethod(t *testing.T) {
+	var exp interface{}
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/items/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.AddRouteHandler("POST", "/items/:slug", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
 
 	if val != exp {
 		t.Errorf("Expected '%v', got '%v'", exp, val)
 	}
 }
 
-func Test_Router_String_Parameter(t *testing.T) {
-	exp := "v1 GET /users/:id"
+func Test_Router_AddRouteHandler_TypedParameterUnknown(t *testing.T) {
+	exp := "unknown parameter type: 'money' for route: '/prices/:amount<money>'"
+	var val interface{}
 
-	r := new(Router)
-	r.AddRouteHandler("GET", "/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return nil
-	})
-	val := r.String()
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/prices/:amount<money>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
 
-	if val != exp {
+	if fmt.Sprint(val) != exp {
 		t.Errorf("Expected '%v', got '%v'", exp, val)
 	}
 }
 
-func Test_Router_String_ParameterRegex(t *testing.T) {
-	exp := "v1 GET /users/:id{[0-9]+}"
+func Test_Router_AddRouteHandler_TypedParameterDelimiter(t *testing.T) {
+	exp := "missing type delimiter '<' or '>' in: ':id<int' for route: '/users/:id<int'"
+	var val interface{}
 
-	r := new(Router)
-	r.AddRouteHandler("GET", "/users/:id{[0-9]+}", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
-		return nil
-	})
-	val := r.String()
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/users/:id<int", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
 
-	if val != exp {
+	if fmt.Sprint(val) != exp {
 		t.Errorf("Expected '%v', got '%v'", exp, val)
 	}
 }
 
-func Test_Router_String_NestedPath(t *testing.T) {
-	exp := "v1 GET /users/:id{[0-9]+}/groups"
+func Test_Router_AddRouteHandler_TemplateParametersRepeated(t *testing.T) {
+	exp := "overwriting parameter: 'id' for route: '/users/:id/:id.:ext'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/users/:id/:id.:ext", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_TemplateParametersAmbiguous(t *testing.T) {
+	exp := "ambiguous route: '/files/:base.:format' conflicts with route: '/files/:name.:ext'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/files/:name.:ext", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.AddRouteHandler("GET", "/files/:base.:format", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_OptionalConflict(t *testing.T) {
+	exp := "ambiguous route: '/users/:id/:tab?' conflicts with route: '/users/:id'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.AddRouteHandler("GET", "/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_GET_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_POST_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.POST("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "POST"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_PUT_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.PUT("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "PUT"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_DELETE_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.DELETE("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "DELETE"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_HEAD_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.HEAD("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "HEAD"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_PATCH_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.PATCH("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "PATCH"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_OPTIONS_Basic(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.OPTIONS("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "OPTIONS"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Any_Methods(t *testing.T) {
+	exp := "GET;HEAD;POST;PUT;PATCH;DELETE;CONNECT;OPTIONS;TRACE;"
+	val := ""
+
+	r := new(Router)
+	r.Any("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val += req.Method + ";"
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, method := range []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = method
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = "/users"
+
+		mh(nil, req, nil)
+	}
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Match_CustomMethods(t *testing.T) {
+	exp := "error"
+
+	r := new(Router)
+	r.Match([]string{"PROPFIND", "REPORT"}, "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("error")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "REPORT"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/files"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Match_CaseSensitive(t *testing.T) {
+	exp := "propfind;method not allowed"
+
+	r := new(Router)
+	r.Match([]string{"propfind"}, "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("propfind")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	val := []string{}
+	for _, method := range []string{"propfind", "PROPFIND"} {
+		req := new(Request)
+		req.Request = new(http.Request)
+		req.Request.Method = method
+		req.ApiVersion = &Version{}
+		req.Request.URL = new(url.URL)
+		req.Request.URL.Path = "/files"
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Match_InvalidMethod(t *testing.T) {
+	exp := "invalid method: 'GET /' for route: '/files'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.Match([]string{"GET /"}, "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Match_InvalidMethodNoPartialRegistration(t *testing.T) {
+	exp := 0
+
+	r := new(Router)
+	func() {
+		defer func() { recover() }()
+		r.Match([]string{"GET", "GET /", "POST"}, "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+	val := len(r.GetRoutes())
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Match_EmptyMethod(t *testing.T) {
+	exp := "invalid method: '' for route: '/files'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.Match([]string{""}, "/files", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddRouteHandler_Middlewares(t *testing.T) {
+	exp := "a:7;b:7;handler:7"
+	val := []string{}
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "handler:"+pms["id"])
+		return nil
+	}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "a:"+pms["id"])
+		return nil
+	}, nil, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "b:"+pms["id"])
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users/7"
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddRouteHandler_MiddlewaresNotMatched(t *testing.T) {
+	exp := "groups"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "users")
+		return nil
+	}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "middleware")
+		return nil
+	})
+	r.GET("/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "groups")
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/groups"
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddRouteHandler_MiddlewaresResponseComplete(t *testing.T) {
+	exp := ErrResponseComplete
+	gvar = 0
+
+	r := new(Router)
+	r.POST("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		gvar = 3
+		return nil
+	}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return ErrResponseComplete
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "POST"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users"
+
+	err := mh(nil, req, nil)
+
+	if err != exp || gvar != 0 {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_AddRouteHandler_PanicTypedError(t *testing.T) {
+	exp := "/users"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	err, _ := val.(error)
+	var dup *DuplicateRouteError
+	if !errors.As(err, &dup) || dup.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_Basic(t *testing.T) {
+	exp := "/users"
+
+	r := new(Router)
+	err := r.TryAddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, _, _, nf := r.getRouteHandler("GET", "/users", &Version{}, nil)
+
+	if err != nil || nf != nil {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_Duplicate(t *testing.T) {
+	exp := "/users/:id"
+
+	r := new(Router)
+	r.TryAddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.TryAddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *DuplicateRouteError
+	if !errors.As(err, &val) || val.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_Ambiguous(t *testing.T) {
+	exp := "/items/:id/details"
+
+	r := new(Router)
+	r.TryAddRouteHandler("GET", "/items/:id/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.TryAddRouteHandler("GET", "/items/:slug/details", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *AmbiguousRouteError
+	if !errors.As(err, &val) || val.Conflict != exp || val.Route != "/items/:slug/details" {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_InvalidPattern(t *testing.T) {
+	exp := "error parsing regexp: unexpected ): `a)b`"
+
+	r := new(Router)
+	err := r.TryAddRouteHandler("GET", "/users/:id{a)b}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *InvalidPatternError
+	if !errors.As(err, &val) || val.Route != "/users/:id{a)b}" || val.Msg != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_UnknownType(t *testing.T) {
+	exp := "unknown parameter type: 'slug' for route: '/users/:id<slug>'"
+
+	r := new(Router)
+	err := r.TryAddRouteHandler("GET", "/users/:id<slug>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *InvalidPatternError
+	if !errors.As(err, &val) || val.Msg != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_InvalidMethod(t *testing.T) {
+	exp := "invalid method: 'GE T' for route: '/users'"
+
+	r := new(Router)
+	err := r.TryAddRouteHandler("GE T", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *InvalidMethodError
+	if !errors.As(err, &val) || val.Msg != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_NoPartialRegistration(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.TryAddRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.TryAddRouteHandler("GET", "/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, _, _, val := r.getRouteHandler("GET", "/users/7/posts", &Version{}, nil)
+
+	if err == nil || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TryAddRouteHandler_Group(t *testing.T) {
+	exp := "/api/users"
+
+	r := new(Router)
+	g := r.Group("/api", Version{})
+	g.TryAddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := g.TryAddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *DuplicateRouteError
+	if !errors.As(err, &val) || val.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryAddNamedRouteHandler_Duplicate(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.TryAddNamedRouteHandler("users", "GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.TryAddNamedRouteHandler("users", "GET", "/people", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, _, _, val := r.getRouteHandler("GET", "/people", &Version{}, nil)
+
+	var dup *DuplicateRouteError
+	if !errors.As(err, &dup) || dup.Route != "/users" || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TryMount_Duplicate(t *testing.T) {
+	exp := "/static"
+
+	r := new(Router)
+	r.TryMount("/static", http.NotFoundHandler())
+	err := r.TryMount("/static/", http.NotFoundHandler())
+
+	var val *DuplicateRouteError
+	if !errors.As(err, &val) || val.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_TryMount_InvalidHandler(t *testing.T) {
+	exp := "invalid handler mounted on prefix: '/static'"
+
+	r := new(Router)
+	err := r.TryMount("/static", 7)
+
+	if err == nil || err.Error() != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Basic(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.RemoveRouteHandler("GET", "/users/:id", Version{})
+	_, _, _, val := r.getRouteHandler("GET", "/users/7", &Version{}, nil)
+
+	if err != nil || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_NotFound(t *testing.T) {
+	exp := "route not found: '/users/:id'"
+
+	r := new(Router)
+	r.GET("/users/:id/posts", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := r.RemoveRouteHandler("GET", "/users/:id", Version{})
+
+	var val *RouteNotFoundError
+	if !errors.As(err, &val) || val.Msg != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_KeepsSiblings(t *testing.T) {
+	exp := "v1 GET /users;v1 GET /users/:id/posts"
+
+	r := new(Router)
+	for _, path := range []string{"/users", "/users/:id", "/users/:id/posts"} {
+		r.GET(path, Version{Major: 1}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}
+	r.RemoveRouteHandler("GET", "/users/:id", Version{Major: 1})
+
+	val := []string{}
+	for _, route := range r.GetRoutes() {
+		val = append(val, route.String())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Optional(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.GET("/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	nf := r.RemoveRouteHandler("GET", "/users/:id", Version{})
+	err := r.RemoveRouteHandler("GET", "/users/:id/:tab?", Version{})
+	_, _, _, val := r.getRouteHandler("GET", "/users/7", &Version{}, nil)
+
+	if nf == nil || err != nil || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_AddAgain(t *testing.T) {
+	exp := "7"
+
+	r := new(Router)
+	r.GET("/items/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.RemoveRouteHandler("GET", "/items/:id", Version{})
+	err := r.TryAddRouteHandler("GET", "/items/:slug", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, p, _, _ := r.getRouteHandler("GET", "/items/7", &Version{}, nil)
+	val := p["slug"]
+
+	if err != nil || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Name(t *testing.T) {
+	exp := "route not found: 'user'"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.RemoveRouteHandler("GET", "/users/:id", Version{})
+	_, err := r.URL("user", map[string]string{"id": "7"})
+
+	if err == nil || err.Error() != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Group(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	g := r.Group("/api", Version{Major: 1})
+	g.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := g.RemoveRouteHandler("GET", "/users", Version{})
+	_, _, _, val := r.getRouteHandler("GET", "/api/users", &Version{Major: 1}, nil)
+
+	if err != nil || val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Snapshot(t *testing.T) {
+	exp := true
+
+	r := new(Router)
+	r.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	table := r.getRouteTable()
+	r.RemoveRouteHandler("GET", "/users/:id", Version{})
+	val := table.trees[""].match(getRouteFragments("GET", "/users/7"), make(map[string]string), nil, nil) != nil
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_ServeSnapshot(t *testing.T) {
+	exp := "users"
+
+	r := new(Router)
+	r.GET("/users/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	table := r.getRouteTable()
+	r.RemoveRouteHandler("GET", "/users/", Version{})
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/users", nil)
+	req.ApiVersion = &Version{}
+
+	err := r.serveRoute(table, nil, req, nil, "/users", nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_ReplaceRouteHandler_Basic(t *testing.T) {
+	exp := 2
+	gvar = 0
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		gvar = 1
+		return nil
+	})
+	err := r.ReplaceRouteHandler("GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		gvar = 2
+		return nil
+	})
+	h, p, _, _ := r.getRouteHandler("GET", "/users/7", &Version{}, nil)
+	(*h)(nil, nil, nil, p)
+	link, _ := r.URL("user", map[string]string{"id": "7"})
+
+	if err != nil || gvar != exp || link != "/users/7" {
+		t.Errorf("Expected '%v', got '%v'", exp, gvar)
+	}
+}
+
+func Test_Router_ReplaceRouteHandler_NotFound(t *testing.T) {
+	exp := "route not found: '/users';route not found: '/users';false"
+
+	r := new(Router)
+	err := r.ReplaceRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.Constrain(RouteConstraints{Headers: map[string]string{"X-Beta": ""}}).GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err2 := r.ReplaceRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	h, _, _, _ := r.getRouteHandler("GET", "/users", &Version{}, httptest.NewRequest("GET", "/users", nil))
+
+	if val := err.Error() + ";" + err2.Error() + ";" + fmt.Sprint(h != nil); val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_ReplaceRouteHandler_Variant(t *testing.T) {
+	exp := "merge2;json;plain"
+	val := []string{}
+
+	r := new(Router)
+	merge := r.Constrain(RouteConstraints{Consumes: []string{"application/merge-patch+json"}})
+	merge.PATCH("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("merge")
+	})
+	r.Constrain(RouteConstraints{Consumes: []string{"application/json-patch+json"}}).PATCH("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("json")
+	})
+	r.PATCH("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("plain")
+	})
+	err := merge.ReplaceRouteHandler("PATCH", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("merge2")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, contentType := range []string{"application/merge-patch+json", "application/json-patch+json", "text/plain"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("PATCH", "/users/7", nil)
+		req.Request.Header.Set("Content-Type", contentType)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if err != nil || strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_SwapRoutes_Basic(t *testing.T) {
+	exp := "GET /posts"
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	o := new(Router)
+	o.GET("/posts", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.SwapRoutes(o)
+	o.GET("/comments", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	val := []string{}
+	for _, route := range r.GetRoutes() {
+		val = append(val, route.String())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_SwapRoutes_Hosts(t *testing.T) {
+	exp := "route not found;route not found"
+	val := []string{}
+
+	live := new(Router)
+	next := new(Router)
+	next.Host("api.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	live.SwapRoutes(next)
+
+	next.Host("api.example.com").GET("/leak", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	live.Host("api.example.com").GET("/live", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	for _, c := range []struct {
+		router *Router
+		path   string
+	}{{live, "/leak"}, {next, "/live"}} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", "http://api.example.com"+c.path, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, c.router.GetMiddlewareHandler()(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_SwapRoutes_HostHandle(t *testing.T) {
+	exp := "late;api.example.com/late"
+
+	live := new(Router)
+	ha := live.Host("api.example.com")
+	ha.GET("/early", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	next := new(Router)
+	next.Host("api.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	live.SwapRoutes(next)
+	ha.GET("/late", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("late")
+	})
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "http://api.example.com/late", nil)
+	req.ApiVersion = &Version{}
+	val := live.GetMiddlewareHandler()(nil, req, nil).Error()
+	for _, route := range live.GetRoutes() {
+		if route.Path == "api.example.com/late" {
+			val += ";" + route.Path
+		}
+	}
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_RemoveRouteHandler_Concurrent(t *testing.T) {
+	exp := http.StatusOK
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			path := "/flags/" + strconv.Itoa(i)
+			r.GET(path, Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+				return nil
+			})
+			r.RemoveRouteHandler("GET", path, Version{})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			req := new(Request)
+			req.Request = httptest.NewRequest("GET", "/users", nil)
+			req.ApiVersion = &Version{}
+			rec := httptest.NewRecorder()
+			if err := mh(nil, req, &Response{ResponseWriter: rec}); err != nil || rec.Code != exp {
+				t.Errorf("Expected '%v', got '%v'", exp, rec.Code)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func Test_Router_Group_RouteMiddlewares(t *testing.T) {
+	exp := "group;route;handler"
+	val := []string{}
+
+	r := new(Router)
+	g := r.Group("/api", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "group")
+		return nil
+	})
+	g.Match([]string{"GET", "POST"}, "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "handler")
+		return nil
+	}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "route")
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "POST"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/api/users"
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Group_PrefixAndVersion(t *testing.T) {
+	exp := "id:7"
+
+	r := new(Router)
+	g := r.Group("/api/", Version{1, 0, 0})
+	g.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("id:" + pms["id"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{1, 0, 0}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/api/users/7"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Group_OverrideVersion(t *testing.T) {
+	exp := "v2 GET /api/users"
+
+	r := new(Router)
+	g := r.Group("/api", Version{1, 0, 0})
+	g.GET("/users", Version{2, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Group_Middlewares(t *testing.T) {
+	exp := "a:7;b:7;handler:7"
+	val := []string{}
+
+	r := new(Router)
+	g := r.Group("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "a:"+pms["id"])
+		return nil
+	})
+	n := g.Group("/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "b:"+pms["id"])
+		return nil
+	})
+	n.GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "handler:"+pms["id"])
+		return nil
+	})
+	r.GET("/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "groups")
+		return nil
+	})
+	mh := g.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/users/7"
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Group_MiddlewareError(t *testing.T) {
+	exp := "unauthorized"
+
+	r := new(Router)
+	g := r.Group("/admin", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("unauthorized")
+	})
+	g.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/admin/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_Basic(t *testing.T) {
+	exp := "/users/7/groups/a%20b"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("groups", "GET", "/users/:uid{[0-9]+}/groups/:gid", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val, err := r.URL("groups", map[string]string{"uid": "7", "gid": "a b"})
+
+	if val != exp || err != nil {
+		t.Errorf("Expected '%v', got '%v' '%v'", exp, val, err)
+	}
+}
+
+func Test_Router_URL_Root(t *testing.T) {
+	exp := "/"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("root", "GET", "/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val, err := r.URL("root", nil)
+
+	if val != exp || err != nil {
+		t.Errorf("Expected '%v', got '%v' '%v'", exp, val, err)
+	}
+}
+
+func Test_Router_URL_Wildcard(t *testing.T) {
+	exp := "/files/css/main.css;/files"
+	val := []string{}
+
+	r := new(Router)
+	r.AddNamedRouteHandler("files", "GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	for _, p := range []string{"css/main.css", ""} {
+		u, _ := r.URL("files", map[string]string{"filepath": p})
+		val = append(val, u)
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_URL_Group(t *testing.T) {
+	exp := "/api/users/7"
+
+	r := new(Router)
+	g := r.Group("/api", Version{})
+	g.AddNamedRouteHandler("user", "GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val, err := r.URL("user", map[string]string{"id": "7"})
+
+	if val != exp || err != nil {
+		t.Errorf("Expected '%v', got '%v' '%v'", exp, val, err)
+	}
+}
+
+func Test_Router_URL_NotFound(t *testing.T) {
+	exp := "route not found: 'user'"
+
+	r := new(Router)
+	_, err := r.URL("user", nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_MissingParameter(t *testing.T) {
+	exp := "missing parameter: 'id' for route: '/users/:id'"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, err := r.URL("user", map[string]string{})
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_InvalidParameter(t *testing.T) {
+	exp := "invalid value: 'abc' for parameter: 'id' for route: '/users/:id{[0-9]+}'"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, err := r.URL("user", map[string]string{"id": "abc"})
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_InvalidTypedParameter(t *testing.T) {
+	exp := "invalid value: 'abc' for parameter: 'id' for route: '/users/:id<int>'"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id<int>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, err := r.URL("user", map[string]string{"id": "abc"})
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_TemplateParameters(t *testing.T) {
+	exp := "/reports/report-2024-05.csv"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("report", "GET", "/reports/report-:year{[0-9]{4}}-:month{[0-9]{2}}.csv", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val, _ := r.URL("report", map[string]string{"year": "2024", "month": "05"})
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_InvalidTemplateParameter(t *testing.T) {
+	exp := "invalid value: '5' for parameter: 'month' for route: '/reports/report-:year{[0-9]{4}}-:month{[0-9]{2}}.csv'"
+
+	r := new(Router)
+	r.AddNamedRouteHandler("report", "GET", "/reports/report-:year{[0-9]{4}}-:month{[0-9]{2}}.csv", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	_, err := r.URL("report", map[string]string{"year": "2024", "month": "5"})
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_URL_Optional(t *testing.T) {
+	exp := "/users/7;/users/7/groups"
+	val := []string{}
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	for _, params := range []map[string]string{{"id": "7"}, {"id": "7", "tab": "groups"}} {
+		path, _ := r.URL("user", params)
+		val = append(val, path)
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_VersionURL_Basic(t *testing.T) {
+	exp := "/users/7;/accounts/7;route not found: 'user'"
+	val := []string{}
+
+	r := new(Router)
+	r.AddNamedRouteHandler("user", "GET", "/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.AddNamedRouteHandler("user", "GET", "/accounts/:id", Version{2, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	for _, v := range []Version{{1, 0, 0}, {2, 0, 0}, {3, 0, 0}} {
+		u, err := r.VersionURL("user", v, map[string]string{"id": "7"})
+		if err != nil {
+			u = err.Error()
+		}
+		val = append(val, u)
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_AddNamedRouteHandler_Duplicate(t *testing.T) {
+	exp := "route name: 'user' already exists for route: 'GET /users/:id'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddNamedRouteHandler("user", "GET", "/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.AddNamedRouteHandler("user", "GET", "/accounts/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Mount_Router(t *testing.T) {
+	exp := "tenant:acme;id:7"
+
+	r := new(Router)
+	sub := new(Router)
+	sub.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("tenant:" + pms["tenant"] + ";id:" + pms["id"])
+	})
+	r.Mount("/tenants/:tenant", sub)
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/tenants/acme/users/7"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Mount_RouterNotFound(t *testing.T) {
+	exp := "method not allowed"
+
+	r := new(Router)
+	sub := new(Router)
+	sub.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.Mount("/admin", sub)
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "POST"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/admin/users"
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Mount_Handler(t *testing.T) {
+	exp := "/debug/vars;acme"
+
+	r := new(Router)
+	r.Mount("/tenants/:tenant/admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + ";" + r.PathValue("tenant")))
+	}))
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/tenants/acme/admin/debug/vars", nil)
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := rec.Body.String()
+
+	if val != exp || req.URL.Path != "/tenants/acme/admin/debug/vars" {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Mount_RoutesFirst(t *testing.T) {
+	exp := "route;mount"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/admin/health", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "route")
+		return nil
+	})
+	r.Mount("/admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		val = append(val, "mount")
+	}))
+	mh := r.GetMiddlewareHandler()
+
+	for _, path := range []string{"/admin/health", "/admin/users"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", path, nil)
+		req.ApiVersion = &Version{}
+
+		mh(nil, req, &Response{ResponseWriter: httptest.NewRecorder()})
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Mount_Group(t *testing.T) {
+	exp := "group;sub"
+	val := []string{}
+
+	r := new(Router)
+	g := r.Group("/api", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "group")
+		return nil
+	})
+	sub := new(Router)
+	sub.GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "sub")
+		return nil
+	})
+	g.Mount("/billing", sub)
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = new(http.Request)
+	req.Request.Method = "GET"
+	req.ApiVersion = &Version{}
+	req.Request.URL = new(url.URL)
+	req.Request.URL.Path = "/api/billing"
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Mount_Invalid(t *testing.T) {
+	exp := "invalid handler mounted on prefix: '/admin'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.Mount("/admin", "handler")
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Mount_Duplicate(t *testing.T) {
+	exp := "mount already exists for prefix: '/admin'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.Mount("/admin", new(Router))
+		r.Mount("/admin/", new(Router))
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Host_Parameters(t *testing.T) {
+	exp := "tenant:acme;id:7"
+
+	r := new(Router)
+	r.Host(":tenant.example.com").GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("tenant:" + pms["tenant"] + ";id:" + pms["id"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/users/7", nil)
+	req.Request.Host = "ACME.example.com:8080"
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Host_Fallback(t *testing.T) {
+	exp := "admin:users;default:users;default:groups"
+	val := []string{}
+
+	r := new(Router)
+	r.Host("admin.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("admin:users")
+	})
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("default:users")
+	})
+	r.GET("/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("default:groups")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"http://admin.example.com/users", "http://api.example.com/users", "http://admin.example.com/groups"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Host_Priority(t *testing.T) {
+	exp := "static;tenant:acme;route not found"
+	val := []string{}
+
+	r := new(Router)
+	r.Host(":tenant{[a-z]+}.example.com").GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("tenant:" + pms["tenant"])
+	})
+	r.Host("admin.example.com").GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("static")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"http://admin.example.com/", "http://acme.example.com/", "http://acme1.example.com/"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Host_Group(t *testing.T) {
+	exp := "group;tenant:acme"
+	val := []string{}
+
+	r := new(Router)
+	g := r.Group("/api", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "group")
+		return nil
+	})
+	g.Host(":tenant.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "tenant:"+pms["tenant"])
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "http://acme.example.com/api/users", nil)
+	req.ApiVersion = &Version{}
+
+	mh(nil, req, nil)
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TypedParameters(t *testing.T) {
+	exp := "int:7;uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8;date:2024-02-29;name:abc"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users/:id<int>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		id, _ := req.IntParam("id")
+		return errors.New("int:" + strconv.FormatInt(id, 10))
+	})
+	r.GET("/users/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("name:" + pms["name"])
+	})
+	r.GET("/orders/:uid<uuid>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		uid, _ := req.UUIDParam("uid")
+		return errors.New("uuid:" + uid.String())
+	})
+	r.GET("/reports/:day<date>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		day, _ := req.DateParam("day")
+		return errors.New("date:" + day.Format("2006-01-02"))
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users/7", "/orders/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "/reports/2024-02-29", "/users/abc"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TypedParameters_Invalid(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.GET("/reports/:day<date>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/reports/2023-02-29", nil)
+	req.ApiVersion = &Version{}
+
+	val := mh(nil, req, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddParamConverter(t *testing.T) {
+	exp := "on:true"
+
+	r := new(Router)
+	g := r.Group("/flags", Version{})
+	g.AddParamConverter("bool", func(s string) (interface{}, error) {
+		return strconv.ParseBool(s)
+	})
+	g.GET("/:name/:on<bool>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		on, _ := req.Param("on")
+		return fmt.Errorf("on:%v", on)
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/flags/beta/true", nil)
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddParamConverter_Invalid(t *testing.T) {
+	exp := "invalid converter for parameter type: 'bool'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.AddParamConverter("bool", nil)
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddParamConverter_Host(t *testing.T) {
+	exp := "on:true"
+
+	r := new(Router)
+	h := r.Host("flags.example.com")
+	r.AddParamConverter("bool", func(s string) (interface{}, error) {
+		return strconv.ParseBool(s)
+	})
+	h.GET("/:on<bool>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		on, _ := req.Param("on")
+		return fmt.Errorf("on:%v", on)
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "http://flags.example.com/true", nil)
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_AddParamConverter_Concurrent(t *testing.T) {
+	exp := 3
+
+	r := new(Router)
+	var wg sync.WaitGroup
+	wg.Add(exp)
+	go func() {
+		defer wg.Done()
+		r.Host("api.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		r.GET("/users/:id<int>", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+		r.SetPathPolicy(PathPolicy{CleanPath: true})
+	}()
+	go func() {
+		defer wg.Done()
+		r.AddParamConverter("bool", func(s string) (interface{}, error) {
+			return strconv.ParseBool(s)
+		})
+		r.getPathPolicy()
+	}()
+	wg.Wait()
+	val := len(r.GetRoutes()) + len(r.getRouteTable().converters)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TemplateParameters(t *testing.T) {
+	exp := "file:archive.tar:gz;report:2024:05;user:abc"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/files/:name.:ext", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("file:" + pms["name"] + ":" + pms["ext"])
+	})
+	r.GET("/reports/report-:year{[0-9]{4}}-:month{[0-9]{2}}.csv", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("report:" + pms["year"] + ":" + pms["month"])
+	})
+	r.GET("/reports/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("user:" + pms["name"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/files/archive.tar.gz", "/reports/report-2024-05.csv", "/reports/abc"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TemplateParameters_Typed(t *testing.T) {
+	exp := "7:json;route not found"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users/:id<int>.:format", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		id, _ := req.IntParam("id")
+		return errors.New(strconv.FormatInt(id, 10) + ":" + pms["format"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users/7.json", "/users/abc.json"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TemplateParameters_SingleParameterName(t *testing.T) {
+	exp := "7"
+
+	r := new(Router)
+	r.GET("/users/:user-id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New(pms["user-id"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/users/7", nil)
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Host_TemplateParameters(t *testing.T) {
+	exp := "eu:2"
+
+	r := new(Router)
+	r.Host("api-:region{[a-z]+}-:n.example.com").GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New(pms["region"] + ":" + pms["n"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "http://api-eu-2.example.com/", nil)
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Host_TypedParameters(t *testing.T) {
+	exp := "7:true;route not found"
+	val := []string{}
+
+	r := new(Router)
+	r.Host(":id<int>.example.com").GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		id, ok := req.IntParam("id")
+		return fmt.Errorf("%v:%v", id, ok)
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, host := range []string{"7.example.com", "abc.example.com"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", "http://"+host+"/", nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Host_UnknownType(t *testing.T) {
+	exp := "unknown parameter type: 'bogus' for route: ':id<bogus>.example.com'"
+	var val interface{}
+
+	func() {
+		defer func() { val = recover() }()
+		r := new(Router)
+		r.Host(":id<bogus>.example.com")
+	}()
+
+	if fmt.Sprint(val) != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TryHost_InvalidPattern(t *testing.T) {
+	exp := ":id<bogus>.example.com"
+
+	r := new(Router)
+	h, err := r.Group("/api", Version{}).TryHost(":id<bogus>.example.com")
+
+	var val *InvalidPatternError
+	if h != nil || !errors.As(err, &val) || val.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_Optional(t *testing.T) {
+	exp := "7:false:;7:true:groups;route not found"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		_, ok := pms["tab"]
+		return fmt.Errorf("%v:%v:%v", pms["id"], ok, pms["tab"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users/7", "/users/7/groups", "/users/7/groups/admin"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Optional_Middle(t *testing.T) {
+	exp := "en:7;:7"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/:lang{[a-z]{2}}?/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New(pms["lang"] + ":" + pms["id"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/en/users/7", "/users/7"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TrailingSlash_Lenient(t *testing.T) {
+	exp := "users;users;groups;groups"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	r.GET("/groups/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("groups")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users", "/users/", "/groups/", "/groups"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TrailingSlash_Distinct(t *testing.T) {
+	exp := "users;users/"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	r.GET("/users/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users/")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users", "/users/"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_TrailingSlash_Strict(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{TrailingSlash: StrictTrailingSlash})
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/users/", nil)
+	req.ApiVersion = &Version{}
+
+	val := mh(nil, req, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_TrailingSlash_Redirect(t *testing.T) {
+	exp := "308;/api/users?page=2"
+
+	r := new(Router)
+	g := r.Group("/api", Version{})
+	g.SetPathPolicy(PathPolicy{TrailingSlash: RedirectTrailingSlash, RedirectCode: http.StatusPermanentRedirect})
+	g.POST("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("POST", "/api/users/?page=2", nil)
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Redirect_ProtocolRelative(t *testing.T) {
+	exp := "301;/evil.com"
+
+	r := new(Router)
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+
+	r.redirect(req, &Response{ResponseWriter: rec}, "//evil.com")
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_CleanPath_Redirect(t *testing.T) {
+	exp := "301;/admin/"
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{CleanPath: true})
+	r.GET("/admin/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/", nil)
+	req.Request.URL.Path = "//users/./../admin/"
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_CleanPath_Backslash(t *testing.T) {
+	exp := "301;/%5Cevil.com"
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{CleanPath: true})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/", nil)
+	req.Request.URL.Path = "/\\evil.com/a/.."
+	req.Request.URL.RawPath = "/\\evil.com/a/.."
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_CleanPath_EncodedDots(t *testing.T) {
+	exp := "301;/x"
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{CleanPath: true})
+	r.GET("/files/*fp", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New(pms["fp"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/files/%2e%2E/x", nil)
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_CleanPath_Disabled(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.GET("/admin", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/", nil)
+	req.Request.URL.Path = "/users/../admin"
+	req.ApiVersion = &Version{}
+
+	val := mh(nil, req, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Case_Strict(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/Users/123", nil)
+	req.ApiVersion = &Version{}
+
+	val := mh(nil, req, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Case_Lenient(t *testing.T) {
+	exp := "user:AbC;users;Users"
+	val := []string{}
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{Case: LenientCase})
+	r.GET("/users/:id/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("user:" + pms["id"])
+	})
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	r.GET("/Users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("Users")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/USERS/AbC/Groups", "/users", "/Users"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Case_Redirect(t *testing.T) {
+	exp := "301;/users/AbC/groups?tab=1"
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{Case: RedirectCase})
+	r.GET("/users/:id/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/Users/AbC/GROUPS?tab=1", nil)
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Case_RedirectTrailingSlash(t *testing.T) {
+	exp := "308;/admin/users"
+
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{TrailingSlash: RedirectTrailingSlash, Case: LenientCase, RedirectCode: http.StatusPermanentRedirect})
+	r.Host("admin.example.com").GET("/admin/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "http://admin.example.com/Admin/Users/", nil)
+	req.ApiVersion = &Version{}
+	rec := httptest.NewRecorder()
+
+	mh(nil, req, &Response{ResponseWriter: rec})
+	val := fmt.Sprintf("%v;%v", rec.Code, rec.Header().Get("Location"))
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Case_Mount(t *testing.T) {
+	exp := "users"
+
+	s := new(Router)
+	s.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("users")
+	})
+	r := new(Router)
+	r.SetPathPolicy(PathPolicy{Case: LenientCase})
+	r.Mount("/admin", s)
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/ADMIN/users", nil)
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Encoding_Parameters(t *testing.T) {
+	exp := "file:a/b;file:100%;path:x y/z;café"
+	val := []string{}
+
+	r := new(Router)
+	r.GET("/files/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("file:" + pms["name"])
+	})
+	r.GET("/paths/*path", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("path:" + pms["path"])
+	})
+	r.GET("/café", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("café")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/files/a%2Fb", "/files/100%25", "/paths/x%20y/z", "/caf%C3%A9"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Encoding_Invalid(t *testing.T) {
+	exp := "invalid encoding: '%zz' in path: '/files/%zz'"
+
+	r := new(Router)
+	r.GET("/files/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/", nil)
+	req.Request.URL.Path = "/files/%zz"
+	req.Request.URL.RawPath = "/files/%zz"
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := ""
+	if _, ok := err.(*InvalidEncodingError); ok {
+		val = err.Error()
+	}
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Encoding_Mount(t *testing.T) {
+	exp := "user:a/b;/users/a%2Fb"
+	val := []string{}
+
+	s := new(Router)
+	s.GET("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		val = append(val, "user:"+pms["id"])
+		return nil
+	})
+	r := new(Router)
+	r.Mount("/admin", s)
+	r.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		val = append(val, r.URL.EscapedPath())
+	}))
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/admin/users/a%2Fb", "/static/users/a%2Fb"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		req.ApiVersion = &Version{}
+
+		mh(nil, req, &Response{ResponseWriter: httptest.NewRecorder()})
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Constrain_ContentType(t *testing.T) {
+	exp := "merge;json;unsupported media type"
+	val := []string{}
+
+	r := new(Router)
+	r.Constrain(RouteConstraints{Consumes: []string{"application/merge-patch+json"}}).PATCH("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("merge")
+	})
+	r.Constrain(RouteConstraints{Consumes: []string{"application/json-patch+json"}}).PATCH("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("json")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, contentType := range []string{"application/merge-patch+json", "application/json-patch+json; charset=utf-8", "text/plain"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("PATCH", "/users/7", nil)
+		req.Request.Header.Set("Content-Type", contentType)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Constrain_Accept(t *testing.T) {
+	exp := "csv;default;not acceptable"
+	val := []string{}
+
+	r := new(Router)
+	r.Constrain(RouteConstraints{Produces: []string{"text/csv"}}).GET("/reports", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("csv")
+	})
+	r.Constrain(RouteConstraints{Produces: []string{"application/json"}}).GET("/reports", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("default")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, accept := range []string{"text/csv", "application/*;q=0.5, text/csv;q=0", "image/png"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", "/reports", nil)
+		req.Request.Header.Set("Accept", accept)
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Constrain_HeaderAndQuery(t *testing.T) {
+	exp := "beta;preview;default"
+	val := []string{}
+
+	r := new(Router)
+	r.Constrain(RouteConstraints{Headers: map[string]string{"X-Beta": "1"}}).GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("beta")
+	})
+	r.Constrain(RouteConstraints{Queries: map[string]string{"preview": ""}}).GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("preview")
+	})
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("default")
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, target := range []string{"/users", "/users?preview", "/users"} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", target, nil)
+		if len(val) == 0 {
+			req.Request.Header.Set("X-Beta", "1")
+		}
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Constrain_NotFound(t *testing.T) {
+	exp := ErrRouteNotFound
+
+	r := new(Router)
+	r.Constrain(RouteConstraints{Headers: map[string]string{"X-Beta": ""}}).GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("GET", "/users", nil)
+	req.ApiVersion = &Version{}
+
+	val := mh(nil, req, nil)
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Constrain_Group(t *testing.T) {
+	exp := "api:7"
+
+	r := new(Router)
+	g := r.Group("/api", Version{}).Constrain(RouteConstraints{Consumes: []string{"application/*"}})
+	g.PUT("/users/:id", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("api:" + pms["id"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	req := new(Request)
+	req.Request = httptest.NewRequest("PUT", "/api/users/7", nil)
+	req.Request.Header.Set("Content-Type", "application/json")
+	req.ApiVersion = &Version{}
+
+	err := mh(nil, req, nil)
+	val := err.Error()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_Constrain_Backtrack(t *testing.T) {
+	exp := "id:5;name:5"
+	val := []string{}
+
+	r := new(Router)
+	r.Constrain(RouteConstraints{Headers: map[string]string{"X-Internal": ""}}).GET("/items/:id{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("id:" + pms["id"])
+	})
+	r.GET("/items/:name", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return errors.New("name:" + pms["name"])
+	})
+	mh := r.GetMiddlewareHandler()
+
+	for _, internal := range []bool{true, false} {
+		req := new(Request)
+		req.Request = httptest.NewRequest("GET", "/items/5", nil)
+		if internal {
+			req.Request.Header.Set("X-Internal", "1")
+		}
+		req.ApiVersion = &Version{}
+
+		val = append(val, mh(nil, req, nil).Error())
+	}
+
+	if strings.Join(val, ";") != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, strings.Join(val, ";"))
+	}
+}
+
+func Test_Router_Constrain_Duplicate(t *testing.T) {
+	exp := "/users"
+
+	r := new(Router)
+	c := r.Constrain(RouteConstraints{Headers: map[string]string{"X-Beta": ""}})
+	c.TryAddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	err := c.TryAddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	var val *DuplicateRouteError
+	if !errors.As(err, &val) || val.Route != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, err)
+	}
+}
+
+func Test_Router_String_Basic(t *testing.T) {
+	exp := "GET /users"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Version(t *testing.T) {
+	exp := "v1 GET /users"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Parameter(t *testing.T) {
+	exp := "v1 GET /users/:id"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_ParameterRegex(t *testing.T) {
+	exp := "v1 GET /users/:id{[0-9]+}"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/users/:id{[0-9]+}", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_NestedPath(t *testing.T) {
+	exp := "v1 GET /users/:id{[0-9]+}/groups"
 
 	r := new(Router)
 	r.AddRouteHandler("GET", "/users/:id{[0-9]+}/groups", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
@@ -619,3 +3552,208 @@ func Test_Router_String_NestedPath(t *testing.T) {
 		t.Errorf("Expected '%v', got '%v'", exp, val)
 	}
 }
+
+func Test_Router_String_Wildcard(t *testing.T) {
+	exp := "GET /files/*filepath"
+
+	r := new(Router)
+	r.AddRouteHandler("GET", "/files/*filepath", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_TypedParameter(t *testing.T) {
+	exp := "GET /users/:id<int>{[1-9][0-9]*}"
+
+	r := new(Router)
+	r.GET("/users/:id<int>{[1-9][0-9]*}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_TemplateParameters(t *testing.T) {
+	exp := "GET /files/:name{[a-z]+}.:ext"
+
+	r := new(Router)
+	r.GET("/files/:name{[a-z]+}.:ext", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Optional(t *testing.T) {
+	exp := "GET /users/:id/:tab?\nGET /users/:id/edit"
+
+	r := new(Router)
+	r.GET("/users/:id/:tab?", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.GET("/users/:id/edit", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_TrailingSlash(t *testing.T) {
+	exp := "GET /api/users/"
+
+	r := new(Router)
+	r.Group("/api", Version{}).GET("/users/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Multiple(t *testing.T) {
+	exp := "GET /\nGET /users\nGET /users/:id\nGET /users/:id/groups"
+
+	r := new(Router)
+	for _, path := range []string{"/users/:id/groups", "/users", "/", "/users/:id"} {
+		r.AddRouteHandler("GET", path, Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+			return nil
+		})
+	}
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Benchmark_Router_getRouteHandler_Static(b *testing.B) {
+	r := new(Router)
+	r.GET("/hello/hello/hello/hello/hello/hello/hello/hello/hello/hello", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.getRouteHandler("GET", "/hello/hello/hello/hello/hello/hello/hello/hello/hello/hello", &Version{}, nil)
+	}
+}
+
+func Benchmark_Router_getRouteHandler_Parameters(b *testing.B) {
+	r := new(Router)
+	r.GET("/users/:uid/groups/:gid", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.getRouteHandler("GET", "/users/7/groups/10", &Version{}, nil)
+	}
+}
+
+func Benchmark_Router_getRouteHandler_Regex(b *testing.B) {
+	r := new(Router)
+	r.GET("/users/:uid{[0-9]+}/groups/:gid{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.getRouteHandler("GET", "/users/7/groups/10", &Version{}, nil)
+	}
+}
+
+func Benchmark_Router_getRouteHandler_NotFound(b *testing.B) {
+	r := new(Router)
+	r.GET("/users/:uid{[0-9]+}", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.getRouteHandler("GET", "/users/abc", &Version{}, nil)
+	}
+}
+
+func Test_Router_String_Group(t *testing.T) {
+	exp := "GET /health\nv1 GET /api/users\nv1 POST /api/users/:id/groups"
+
+	r := new(Router)
+	r.GET("/health", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	g := r.Group("/api", Version{1, 0, 0})
+	g.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	g.Group("/users/:id", Version{}).POST("/groups", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := g.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Mount(t *testing.T) {
+	exp := "GET /health\nGET /admin\nv1 POST /admin/users/:id\n/debug/pprof/*"
+
+	r := new(Router)
+	r.GET("/health", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	sub := new(Router)
+	sub.GET("/", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	sub.POST("/users/:id", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.Mount("/admin", sub)
+	r.Mount("/debug/pprof", http.NotFoundHandler())
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}
+
+func Test_Router_String_Host(t *testing.T) {
+	exp := "GET /users\nv1 POST admin.example.com/users\nGET :tenant.example.com/users"
+
+	r := new(Router)
+	r.GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.Host(":tenant.example.com").GET("/users", Version{}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	r.Host("Admin.Example.com").POST("/users", Version{1, 0, 0}, func(app *App, req *Request, res *Response, pms map[string]string) error {
+		return nil
+	})
+	val := r.String()
+
+	if val != exp {
+		t.Errorf("Expected '%v', got '%v'", exp, val)
+	}
+}